@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	attachCmd = &cobra.Command{
+		Use:   "attach <Work Item ID> <File, ...>",
+		Short: "Attach files to work item",
+		Long:  "Upload files and attach them to work item.",
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = attachCommand(cmd.Context(), workItemID, args[1:])
+			cobra.CheckErr(err)
+		},
+	}
+
+	attachmentsCmd = &cobra.Command{
+		Use:   "attachments <Work Item ID>",
+		Short: "List work item attachments",
+		Long:  "List or download work item attachments.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = attachmentsCommand(cmd.Context(), workItemID)
+			cobra.CheckErr(err)
+		},
+	}
+
+	attachmentsCmdFlagDownloadDir string
+)
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(attachmentsCmd)
+
+	attachmentsCmd.Flags().StringVarP(&attachmentsCmdFlagDownloadDir, "download", "d", "", "Download attachments into specified directory")
+	cobra.CheckErr(attachmentsCmd.MarkFlagDirname("download"))
+}
+
+func attachCommand(ctx context.Context, workItemID int, files []string) error {
+	spinner, _ := pterm.DefaultSpinner.Start("Uploading...")
+	defer func() {
+		_ = spinner.Stop()
+	}()
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := a.WiClient.AddAttachments(ctx, workItemID, files)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+
+	spinner.Success(fmt.Sprintf("%d file(s) attached to %d", len(attachments), workItemID))
+	return nil
+}
+
+func attachmentsCommand(ctx context.Context, workItemID int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	w, err := a.WiClient.GetExpanded(ctx, workItemID)
+	if err != nil {
+		return err
+	}
+
+	attachments := workitem.GetAttachments(w)
+	if len(attachments) == 0 {
+		fmt.Println("no attachments")
+		return nil
+	}
+
+	if attachmentsCmdFlagDownloadDir == "" {
+		tableData := [][]string{{"Name", "Size", "Comment", "URL"}}
+		for _, attachment := range attachments {
+			tableData = append(tableData, []string{
+				attachment.Name,
+				strconv.Itoa(attachment.Size),
+				attachment.Comment,
+				attachment.URL,
+			})
+		}
+
+		return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	}
+
+	err = os.MkdirAll(attachmentsCmdFlagDownloadDir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		err = downloadAttachment(ctx, a, attachment, filepath.Join(attachmentsCmdFlagDownloadDir, filepath.Base(attachment.Name)))
+		if err != nil {
+			pterm.Error.Println(fmt.Sprintf("NOT DOWNLOADED %s: %s", attachment.Name, err.Error()))
+		} else {
+			pterm.Success.Println(fmt.Sprintf("DOWNLOADED %s", attachment.Name))
+		}
+	}
+
+	return nil
+}
+
+func downloadAttachment(ctx context.Context, a *tfs.API, attachment workitem.Attachment, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = a.WiClient.DownloadAttachment(ctx, attachment, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// attachFiles attaches files specified by --attach flag to created work item.
+func attachFiles(ctx context.Context, a *tfs.API, task *workitemtracking.WorkItem, files []string) error {
+	if task == nil || len(files) == 0 {
+		return nil
+	}
+
+	_, err := a.WiClient.AddAttachments(ctx, *task.Id, files)
+	return err
+}
@@ -38,12 +38,18 @@ var bugfixCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		cobra.CheckErr(applyWorkItemTemplateDefaults(cmd))
+
 		bugID, _ := strconv.Atoi(args[0])
 		description := ""
 		if len(args) > 1 {
 			description = args[1]
 		}
-		err := createBugfixCommand(cmd.Context(), bugID, description)
+
+		description, err := getDescription(description, createTaskCmdFlagDescriptionFile, fmt.Sprintf("bugfix %d", bugID), createTaskCmdFlagHTMLDescription, !createTaskCmdFlagNoEdit)
+		cobra.CheckErr(err)
+
+		err = createBugfixCommand(cmd.Context(), bugID, description)
 		cobra.CheckErr(err)
 	},
 }
@@ -73,7 +79,7 @@ func createBugfixCommand(ctx context.Context, bugID int, description string) err
 	relations := []*workitem.Relation{
 		{
 			URL:  *bug.Url,
-			Type: "System.LinkTypes.Related",
+			Type: workitem.RelatedLinkType,
 		},
 	}
 
@@ -82,6 +88,22 @@ func createBugfixCommand(ctx context.Context, bugID int, description string) err
 		return err
 	}
 
+	if createTaskCmdFlagTemplate != "" {
+		task, children, err := createWorkItemByTemplate(ctx, a, createTaskCmdFlagTemplate, workItemTemplateData{
+			Title:       title,
+			Description: description,
+			Bug:         newWorkItemTemplateWorkItem(bug),
+		}, parentUserStoryNamePattern, relations, false)
+		if err == nil {
+			err = attachFiles(ctx, a, task, createTaskCmdFlagAttachments)
+		}
+		printCreateTaskResult(task, err, spinner)
+		printCreatedChildren(children)
+		openInBrowser(task)
+
+		return err
+	}
+
 	task, err := a.CreateWorkItem(
 		ctx,
 		createTaskCmdFlagWorkitemType,
@@ -95,6 +117,9 @@ func createBugfixCommand(ctx context.Context, bugID int, description string) err
 		!createTaskCmdFlagUnassignedTask,
 		false,
 	)
+	if err == nil {
+		err = attachFiles(ctx, a, task, createTaskCmdFlagAttachments)
+	}
 	printCreateTaskResult(task, err, spinner)
 	openInBrowser(task)
 
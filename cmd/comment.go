@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tasker/prettyprint"
+	"tasker/tfs"
+	"tasker/tfs/identity"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commentCmd = &cobra.Command{
+		Use:   "comment <Work Item ID> [Text]",
+		Short: "Add comment to work item",
+		Long: `Add comment to work item discussion.
+Text is written in Markdown and can be specified by argument, file or stdin ("-"), otherwise the editor is opened.
+Users are mentioned by @login or @e-mail.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			var text string
+			if len(args) > 1 {
+				text = args[1]
+			}
+
+			text, err = getDescription(text, commentCmdFlagFile, fmt.Sprintf("comment to %d", workItemID), commentCmdFlagHTML, true)
+			cobra.CheckErr(err)
+
+			err = commentCommand(cmd.Context(), workItemID, text)
+			cobra.CheckErr(err)
+		},
+	}
+
+	discussionCmd = &cobra.Command{
+		Use:   "discussion <Work Item ID>",
+		Short: "Show work item discussion",
+		Long:  "Show work item comments with authors and dates.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = discussionCommand(cmd.Context(), workItemID)
+			cobra.CheckErr(err)
+		},
+	}
+
+	commentCmdFlagFile string
+	commentCmdFlagHTML bool
+
+	mentionRegexp = regexp.MustCompile(`(^|[\s>(])@([\p{L}\p{N}_\-]+(?:[.@][\p{L}\p{N}_\-]+)*)`)
+)
+
+func init() {
+	rootCmd.AddCommand(commentCmd)
+	rootCmd.AddCommand(discussionCmd)
+
+	commentCmd.Flags().StringVarP(&commentCmdFlagFile, "file", "F", "", "Read comment from file (\"-\" for stdin)")
+	commentCmd.Flags().BoolVar(&commentCmdFlagHTML, "html", false, "Comment is HTML, not Markdown")
+}
+
+func commentCommand(ctx context.Context, workItemID int, text string) error {
+	if text == "" {
+		return errors.New("empty comment")
+	}
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	text, err = resolveMentions(ctx, a, text)
+	if err != nil {
+		return err
+	}
+
+	comment, err := a.WiClient.PostComment(ctx, workItemID, text)
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Println(fmt.Sprintf("Comment %d added to %d", comment.ID, workItemID))
+	return nil
+}
+
+func discussionCommand(ctx context.Context, workItemID int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	comments, err := a.WiClient.GetDiscussion(ctx, workItemID)
+	if err != nil {
+		return err
+	}
+
+	if len(comments) == 0 {
+		fmt.Println("no comments")
+		return nil
+	}
+
+	for _, comment := range comments {
+		pterm.DefaultSection.
+			WithLevel(2).
+			Println(fmt.Sprintf("%s, %s", comment.Author, comment.Date.Local().Format("02.01.2006 15:04")))
+		fmt.Println(prettyprint.HTMLText(comment.Text))
+	}
+
+	return nil
+}
+
+// resolveMentions replaces @user references with TFS mention links, unknown users are left as is.
+func resolveMentions(ctx context.Context, a *tfs.API, text string) (string, error) {
+	matches := mentionRegexp.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text, nil
+	}
+
+	mentions := make(map[string]string)
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		nameStart, nameEnd := match[4], match[5]
+		name := text[nameStart:nameEnd]
+
+		mention, ok := mentions[name]
+		if !ok {
+			user, err := identity.Find(ctx, a.Conn, name)
+			switch {
+			case errors.Is(err, identity.ErrNotFound) || errors.Is(err, identity.ErrNotUnique):
+				pterm.Warning.Println(fmt.Sprintf("@%s: %s", name, err.Error()))
+			case err != nil:
+				return "", err
+			default:
+				mention = fmt.Sprintf(`<a href="#" data-vss-mention="version:2.0,%s">@%s</a>`, user.Id, html.EscapeString(user.DisplayName))
+			}
+			mentions[name] = mention
+		}
+
+		if mention == "" {
+			continue
+		}
+
+		// the mention starts with "@" right before the name
+		sb.WriteString(text[last : nameStart-1])
+		sb.WriteString(mention)
+		last = nameEnd
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String(), nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/eiannone/keyboard"
+	"github.com/pterm/pterm"
+)
+
+func requestEnterConfirmation() (bool, error) {
+	pterm.DefaultHeader.
+		WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgDefault)).
+		WithTextStyle(pterm.NewStyle(pterm.FgCyan)).
+		Print("Press ENTER to continue. Any other key for cancel.")
+
+	_, key, err := keyboard.GetSingleKey()
+	if err != nil {
+		return false, err
+	}
+
+	if key != keyboard.KeyEnter {
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+
+	"github.com/pterm/pterm"
+)
+
+func copyWorkItemTreeCommand(ctx context.Context, rootID int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Loading work items...")
+	tree, err := a.WiClient.GetTree(ctx, rootID)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+	_ = spinner.Stop()
+
+	description, err := getDescription(copyWorkItemCmdDescription, copyWorkItemCmdDescriptionFile, workitem.GetTitle(tree.WorkItem), copyWorkItemCmdHTMLDescription, false)
+	if err != nil {
+		return err
+	}
+
+	err = previewWorkItemTreeCopy(tree)
+	if err != nil {
+		return err
+	}
+
+	ok, err := requestEnterConfirmation()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("canceled by user")
+	}
+
+	relations, err := getCopyRootRelations(ctx, a, tree.WorkItem)
+	if err != nil {
+		return err
+	}
+
+	progressbar, err := pterm.DefaultProgressbar.WithTitle("Copying...").WithTotal(tree.Count()).WithRemoveWhenDone().Start()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = progressbar.Stop()
+	}()
+
+	return copyWorkItemTreeNode(ctx, a, tree, relations, description, progressbar)
+}
+
+// copyWorkItemTreeNode copies the node and then its children under the copy.
+func copyWorkItemTreeNode(ctx context.Context, a *tfs.API, node *workitem.TreeNode, relations []*workitem.Relation, description string, progressbar *pterm.ProgressbarPrinter) error {
+	source := node.WorkItem
+	progressbar.UpdateTitle(fmt.Sprintf("Copying %s", cutString(workitem.GetTitle(source), 20, true)))
+
+	task, err := copyWorkItem(ctx, a, source, relations, description)
+	if err != nil {
+		pterm.Error.Println(fmt.Sprintf("NOT COPIED %d %s: %s", *source.Id, workitem.GetTitle(source), err.Error()))
+		return err
+	}
+	pterm.Success.Println(fmt.Sprintf("COPIED %d as %d %s", *source.Id, *task.Id, workitem.GetTitle(task)))
+	progressbar.Increment()
+
+	for _, child := range node.Children {
+		childRelations := []*workitem.Relation{
+			{
+				URL:  *task.Url,
+				Type: workitem.ParentLinkType,
+			},
+		}
+
+		err = copyWorkItemTreeNode(ctx, a, child, childRelations, "", progressbar)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func previewWorkItemTreeCopy(tree *workitem.TreeNode) error {
+	var items pterm.LeveledList
+	var totalEstimate float32
+	_ = tree.Walk(func(node *workitem.TreeNode, depth int) error {
+		text := fmt.Sprintf("[%s] %s", workitem.GetType(node.WorkItem), getCopyTitle(node.WorkItem))
+		if estimate := workitem.GetOriginalEstimate(node.WorkItem); estimate > 0 {
+			text += fmt.Sprintf(" (%v)", estimate)
+			totalEstimate += estimate
+		}
+
+		items = append(items, pterm.LeveledListItem{Level: depth, Text: text})
+		return nil
+	})
+
+	err := pterm.DefaultTree.WithRoot(pterm.NewTreeFromLeveledList(items)).Render()
+	if err != nil {
+		return err
+	}
+
+	area := copyWorkItemCmdAreaPath
+	if area == "" {
+		area = "as source"
+	}
+	iteration := copyWorkItemCmdIterationPath
+	if iteration == "" {
+		iteration = "as source"
+	}
+
+	pterm.DefaultBox.WithTitle("Total").Printfln("Work items: %d\nEstimate: %v\nArea: %s\nIteration: %s", tree.Count(), totalEstimate, area, iteration)
+	return nil
+}
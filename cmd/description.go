@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"tasker/editor"
+	"tasker/markdown"
+
+	"golang.org/x/term"
+)
+
+const descriptionScissors = "# ------------------------ >8 ------------------------"
+
+// getDescription reads work item description from argument, file or stdin ("-") and converts it from Markdown to HTML.
+// If description is not specified and edit is allowed, user's editor is opened like 'git commit' does.
+func getDescription(description, descriptionFile, title string, isHTML, edit bool) (string, error) {
+	var err error
+
+	switch {
+	case description == "-" || descriptionFile == "-":
+		description, err = readAll(os.Stdin)
+	case descriptionFile != "":
+		var content []byte
+		content, err = os.ReadFile(descriptionFile)
+		description = string(content)
+	case description == "" && edit && term.IsTerminal(int(os.Stdin.Fd())):
+		description, err = editDescription(title)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	description = strings.TrimSpace(description)
+	if description == "" || isHTML {
+		return description, nil
+	}
+
+	return markdown.ToHTML(description)
+}
+
+func editDescription(title string) (string, error) {
+	template := fmt.Sprintf("\n\n%s\n# Write description of \"%s\" in Markdown above this line.\n# Everything below the line above is ignored, empty description means the title is used.\n", descriptionScissors, title)
+
+	content, err := editor.Edit(template, ".md")
+	if err != nil {
+		return "", err
+	}
+
+	content, _, _ = strings.Cut(content, descriptionScissors)
+	return content, nil
+}
+
+func readAll(r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
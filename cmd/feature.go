@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+	"tasker/wiki/macros"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+const featureStatusPanelTitle = "Статус задач"
+
+var (
+	featureCmd = &cobra.Command{
+		Use:   "feature",
+		Short: "Manage features",
+		Long:  "Manage features planned on wiki pages.",
+	}
+
+	featureStatusCmd = &cobra.Command{
+		Use:   "status <Feature ID|Wiki page ID>",
+		Short: "Show feature status",
+		Long: `Compare tasks planned on the wiki page with actual child tasks of the feature in TFS:
+tasks without work items, work items missing on the page, estimate deltas, closed and open tasks.
+The wiki page is looked up by feature ID in its title, if it is not specified.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = featureStatusCommand(cmd.Context(), id)
+			cobra.CheckErr(err)
+		},
+	}
+
+	featureStatusCmdFlagPageID     uint
+	featureStatusCmdFlagSpace      string
+	featureStatusCmdFlagUpdatePage bool
+)
+
+func init() {
+	rootCmd.AddCommand(featureCmd)
+	featureCmd.AddCommand(featureStatusCmd)
+
+	featureStatusCmd.Flags().UintVarP(&featureStatusCmdFlagPageID, "page", "w", 0, "ID of feature wiki page")
+	featureStatusCmd.Flags().StringVarP(&featureStatusCmdFlagSpace, "space", "s", "", "Space key to search feature wiki page in")
+	featureStatusCmd.Flags().BoolVar(&featureStatusCmdFlagUpdatePage, "update-page", false, "Insert status section into wiki page")
+}
+
+type featureStatusRow struct {
+	Title      string
+	TfsTaskID  int
+	Planned    float32
+	Estimate   float32
+	Completed  float32
+	State      string
+	IsClosed   bool
+	OnPage     bool
+	IsChild    bool
+	HasTfsTask bool
+}
+
+func (r *featureStatusRow) issue() string {
+	switch {
+	case !r.HasTfsTask && r.TfsTaskID > 0:
+		return "work item not found"
+	case !r.HasTfsTask:
+		return "no work item"
+	case !r.OnPage:
+		return "not on page"
+	case !r.IsChild:
+		return "not a child of feature"
+	case r.Planned != r.Estimate:
+		return fmt.Sprintf("estimate %+g", r.Estimate-r.Planned)
+	}
+	return ""
+}
+
+type featureStatus struct {
+	FeatureID    int
+	FeatureTitle string
+	Page         *goconfluence.Content
+	Rows         []*featureStatusRow
+}
+
+type featureStatusTotal struct {
+	Planned         float32
+	Estimate        float32
+	Completed       float32
+	ClosedEstimate  float32
+	Closed          int
+	Open            int
+	WithoutWorkItem int
+	NotOnPage       int
+}
+
+func (s *featureStatus) total() featureStatusTotal {
+	var total featureStatusTotal
+	for _, row := range s.Rows {
+		total.Planned += row.Planned
+		total.Estimate += row.Estimate
+		total.Completed += row.Completed
+
+		switch {
+		case !row.HasTfsTask:
+			total.WithoutWorkItem++
+		case row.IsClosed:
+			total.Closed++
+			total.ClosedEstimate += row.Estimate
+		default:
+			total.Open++
+		}
+
+		if row.HasTfsTask && !row.OnPage {
+			total.NotOnPage++
+		}
+	}
+	return total
+}
+
+func featureStatusCommand(ctx context.Context, id int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Loading...")
+	status, err := buildFeatureStatus(ctx, a, api, id)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+	_ = spinner.Stop()
+
+	err = printFeatureStatus(status)
+	if err != nil {
+		return err
+	}
+
+	if featureStatusCmdFlagUpdatePage {
+		body := wiki.SetPanel(status.Page.Body.Storage.Value, featureStatusPanelTitle, renderFeatureStatusStorage(a, status))
+		err = api.UploadContent(status.Page.ID, body, "storage")
+		if err != nil {
+			return err
+		}
+		pterm.Success.Println("Wiki page updated")
+	}
+
+	return nil
+}
+
+// buildFeatureStatus resolves feature and its wiki page by any of their IDs and cross-references page tasks with feature children.
+func buildFeatureStatus(ctx context.Context, a *tfs.API, api *wiki.API, id int) (*featureStatus, error) {
+	featureID, page, err := findFeatureAndPage(ctx, a, api, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := a.WiClient.GetTree(ctx, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := parseTasksTable(page, false)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &featureStatus{
+		FeatureID:    featureID,
+		FeatureTitle: workitem.GetTitle(tree.WorkItem),
+		Page:         page,
+	}
+
+	children := make(map[int]*workitemtracking.WorkItem)
+	for _, child := range tree.Children {
+		children[*child.WorkItem.Id] = child.WorkItem
+	}
+
+	// tasks on page may refer work items which are not children of the feature
+	var foreignIDs []int
+	for _, t := range tasks {
+		if _, ok := children[t.TfsTaskID]; t.TfsTaskID > 0 && !ok {
+			foreignIDs = append(foreignIDs, t.TfsTaskID)
+		}
+	}
+
+	foreign := make(map[int]*workitemtracking.WorkItem)
+	if len(foreignIDs) > 0 {
+		workItems, err := a.WiClient.GetList(ctx, foreignIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range workItems {
+			foreign[*workItems[i].Id] = &workItems[i]
+		}
+	}
+
+	onPage := make(map[int]bool)
+	for _, t := range tasks {
+		row := &featureStatusRow{
+			Title:     t.Title,
+			TfsTaskID: t.TfsTaskID,
+			Planned:   t.Estimate,
+			OnPage:    true,
+		}
+
+		w, isChild := children[t.TfsTaskID]
+		if !isChild {
+			w = foreign[t.TfsTaskID]
+		}
+		if w != nil {
+			fillFeatureStatusRow(row, w)
+			row.IsChild = isChild
+			onPage[t.TfsTaskID] = true
+		}
+
+		status.Rows = append(status.Rows, row)
+	}
+
+	for _, child := range tree.Children {
+		if onPage[*child.WorkItem.Id] {
+			continue
+		}
+
+		row := &featureStatusRow{
+			Title:     workitem.GetTitle(child.WorkItem),
+			TfsTaskID: *child.WorkItem.Id,
+			IsChild:   true,
+		}
+		fillFeatureStatusRow(row, child.WorkItem)
+		status.Rows = append(status.Rows, row)
+	}
+
+	return status, nil
+}
+
+func fillFeatureStatusRow(row *featureStatusRow, w *workitemtracking.WorkItem) {
+	row.HasTfsTask = true
+	row.Estimate = workitem.GetOriginalEstimate(w)
+	row.Completed = workitem.GetCompletedWork(w)
+	row.State = workitem.GetState(w)
+	row.IsClosed = workitem.IsCompleted(w)
+}
+
+// findFeatureAndPage treats id as feature ID first and looks up its wiki page, otherwise id is a wiki page ID.
+func findFeatureAndPage(ctx context.Context, a *tfs.API, api *wiki.API, id int) (int, *goconfluence.Content, error) {
+	if featureStatusCmdFlagPageID > 0 {
+		page, err := getPageWithBody(api, strconv.Itoa(int(featureStatusCmdFlagPageID)))
+		return id, page, err
+	}
+
+	if _, err := a.WiClient.Get(ctx, id); err == nil {
+		page, err := findFeaturePage(api, id, featureStatusCmdFlagSpace)
+		return id, page, err
+	}
+
+	page, err := getPageWithBody(api, strconv.Itoa(id))
+	if err != nil {
+		return 0, nil, fmt.Errorf("neither work item nor wiki page %d found", id)
+	}
+
+	featureID, err := strconv.Atoi(featureIDRegexp.FindString(page.Title))
+	if err != nil {
+		return 0, nil, errors.New("unable to determine TFS feature ID from wiki page title")
+	}
+
+	return featureID, page, nil
+}
+
+func getPageWithBody(api *wiki.API, pageID string) (*goconfluence.Content, error) {
+	return api.GetContentByID(pageID, goconfluence.ContentQuery{
+		Expand: []string{
+			"body.storage",
+			"space",
+			"version",
+		},
+	})
+}
+
+// findFeaturePage looks up the wiki page having the feature ID in its title, like 'sync' command expects.
+func findFeaturePage(api *wiki.API, featureID int, spaceKey string) (*goconfluence.Content, error) {
+	pageIDs, err := findFeaturePageIDs(api, featureID, spaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(pageIDs) {
+	case 0:
+		return nil, fmt.Errorf("wiki page of feature %d not found, specify it by --page", featureID)
+	case 1:
+		return getPageWithBody(api, pageIDs[0])
+	default:
+		return nil, fmt.Errorf("found more than one wiki page of feature %d (%s), specify it by --page", featureID, strings.Join(pageIDs, ", "))
+	}
+}
+
+func findFeaturePageIDs(api *wiki.API, featureID int, spaceKey string) ([]string, error) {
+	cql := fmt.Sprintf(`type=page AND title ~ "%d"`, featureID)
+	if spaceKey != "" {
+		cql += fmt.Sprintf(` AND space="%s"`, spaceKey)
+	}
+
+	result, err := api.SearchContent(goconfluence.SearchQuery{
+		CQL:   cql,
+		Limit: 50,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pageIDs []string
+	for _, r := range result.Results {
+		if featureIDRegexp.FindString(r.Title) == strconv.Itoa(featureID) {
+			pageIDs = append(pageIDs, r.ID)
+		}
+	}
+
+	return pageIDs, nil
+}
+
+func printFeatureStatus(status *featureStatus) error {
+	pterm.DefaultSection.Println(fmt.Sprintf("%d %s", status.FeatureID, status.FeatureTitle))
+	pterm.Info.Println(wiki.GetPageURL(status.Page))
+
+	tableData := [][]string{{"Title", "TFS", "State", "Planned", "Estimate", "Completed", "Issue"}}
+	for _, row := range status.Rows {
+		tfsTaskID := ""
+		if row.TfsTaskID > 0 {
+			tfsTaskID = strconv.Itoa(row.TfsTaskID)
+		}
+
+		issue := row.issue()
+		if issue != "" {
+			issue = pterm.FgYellow.Sprint(issue)
+		}
+
+		state := row.State
+		if row.IsClosed {
+			state = pterm.FgGreen.Sprint(state)
+		}
+
+		tableData = append(tableData, []string{
+			cutString(row.Title, 60, false),
+			tfsTaskID,
+			state,
+			formatHours(row.Planned, row.OnPage),
+			formatHours(row.Estimate, row.HasTfsTask),
+			formatHours(row.Completed, row.HasTfsTask),
+			issue,
+		})
+	}
+
+	total := status.total()
+	tableData = append(tableData, []string{"∑", "", "", fmt.Sprintf("%v", total.Planned), fmt.Sprintf("%v", total.Estimate), fmt.Sprintf("%v", total.Completed), ""})
+
+	err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	if err != nil {
+		return err
+	}
+
+	pterm.DefaultBox.WithTitle("Total").Printfln(
+		"Closed: %d, open: %d\nClosed estimate: %v of %v planned\nWithout work item: %d\nNot on page: %d",
+		total.Closed, total.Open, total.ClosedEstimate, total.Planned, total.WithoutWorkItem, total.NotOnPage,
+	)
+
+	return nil
+}
+
+func formatHours(hours float32, known bool) string {
+	if !known {
+		return ""
+	}
+	return fmt.Sprintf("%v", hours)
+}
+
+// renderFeatureStatusStorage renders status as storage markup, states are shown by status badges.
+func renderFeatureStatusStorage(a *tfs.API, status *featureStatus) string {
+	var sb strings.Builder
+	total := status.total()
+
+	sb.WriteString(fmt.Sprintf("<p>Обновлено: %s</p>", time.Now().Format("02.01.2006 15:04")))
+	sb.WriteString(fmt.Sprintf("<p>Закрыто: %d, открыто: %d, без задачи в TFS: %d, нет на странице: %d</p>",
+		total.Closed, total.Open, total.WithoutWorkItem, total.NotOnPage))
+	sb.WriteString(fmt.Sprintf("<p>Оценка закрытых задач: %v из %v запланированных</p>", total.ClosedEstimate, total.Planned))
+
+	sb.WriteString("<table><tbody>")
+	sb.WriteString("<tr><th>Задача</th><th>TFS</th><th>Состояние</th><th>План</th><th>Оценка</th><th>Списано</th><th>Замечание</th></tr>")
+	for _, row := range status.Rows {
+		tfsTask := ""
+		if row.TfsTaskID > 0 {
+			tfsTask = fmt.Sprintf(`<a href="%s">%d</a>`, html.EscapeString(a.GetWorkItemWebURL(row.TfsTaskID)), row.TfsTaskID)
+		}
+
+		state := ""
+		if row.State != "" {
+			colour := "Blue"
+			if row.IsClosed {
+				colour = "Green"
+			}
+			state = renderWikiMacro(macros.Status, macros.Data{ID: row.TfsTaskID, Status: row.State, Colour: colour})
+		}
+
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>",
+			html.EscapeString(row.Title),
+			tfsTask,
+			state,
+			formatHours(row.Planned, row.OnPage),
+			formatHours(row.Estimate, row.HasTfsTask),
+			formatHours(row.Completed, row.HasTfsTask),
+			html.EscapeString(row.issue()),
+		))
+	}
+	sb.WriteString("</tbody></table>")
+
+	return sb.String()
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"tasker/diff"
+	"tasker/prettyprint"
+	"tasker/tfs"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history <Work Item ID>",
+		Short: "Show work item history",
+		Long: `Show work item changes revision by revision: who, when and field values before and after.
+HTML fields like description are compared as text.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			since, err := parseSince(historyCmdFlagSince)
+			cobra.CheckErr(err)
+
+			err = historyCommand(cmd.Context(), workItemID, since)
+			cobra.CheckErr(err)
+		},
+	}
+
+	historyCmdFlagSince     string
+	historyCmdFlagAllFields bool
+
+	htmlTagRegexp = regexp.MustCompile(`<[a-zA-Z][^>]*>`)
+
+	// historyCmdHiddenFields are changed by TFS itself on each revision
+	historyCmdHiddenFields = []string{
+		"System.Rev",
+		"System.Watermark",
+		"System.ChangedDate",
+		"System.ChangedBy",
+		"System.AuthorizedDate",
+		"System.AuthorizedAs",
+		"System.RevisedDate",
+		"System.PersonId",
+		"System.AreaId",
+		"System.IterationId",
+		"System.NodeName",
+		"System.TeamProject",
+		"System.CommentCount",
+		"Microsoft.VSTS.Common.StateChangeDate",
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historyCmdFlagSince, "since", "", "Show changes made since date (2006-01-02 or \"2006-01-02 15:04\") or for period (7d, 12h)")
+	historyCmd.Flags().BoolVar(&historyCmdFlagAllFields, "all-fields", false, "Show system fields changed on each revision too")
+}
+
+func historyCommand(ctx context.Context, workItemID int, since time.Time) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	updates, err := a.WiClient.GetAllUpdates(ctx, workItemID)
+	if err != nil {
+		return err
+	}
+
+	var printed int
+	for i := range updates {
+		update := &updates[i]
+		if getUpdateDate(update).Before(since) {
+			continue
+		}
+
+		if printHistoryUpdate(update) {
+			printed++
+		}
+	}
+
+	if printed == 0 {
+		fmt.Println("no changes")
+	}
+
+	return nil
+}
+
+// printHistoryUpdate prints changes of the revision, it returns false if there is nothing to show.
+func printHistoryUpdate(update *workitemtracking.WorkItemUpdate) bool {
+	var fields []string
+	if update.Fields != nil {
+		for field := range *update.Fields {
+			if historyCmdFlagAllFields || !slices.Contains(historyCmdHiddenFields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	slices.Sort(fields)
+
+	var added, removed []workitemtracking.WorkItemRelation
+	if update.Relations != nil {
+		if update.Relations.Added != nil {
+			added = *update.Relations.Added
+		}
+		if update.Relations.Removed != nil {
+			removed = *update.Relations.Removed
+		}
+	}
+
+	if len(fields) == 0 && len(added) == 0 && len(removed) == 0 {
+		return false
+	}
+
+	var author string
+	if update.RevisedBy != nil && update.RevisedBy.DisplayName != nil {
+		author = *update.RevisedBy.DisplayName
+	}
+
+	var rev int
+	if update.Rev != nil {
+		rev = *update.Rev
+	}
+
+	pterm.DefaultSection.
+		WithLevel(2).
+		Println(fmt.Sprintf("Rev %d, %s, %s", rev, author, getUpdateDate(update).Local().Format("02.01.2006 15:04")))
+
+	tableData := [][]string{{"Field", "Old", "New"}}
+	var textDiffs []string
+	for _, field := range fields {
+		change := (*update.Fields)[field]
+		oldValue := formatHistoryValue(change.OldValue)
+		newValue := formatHistoryValue(change.NewValue)
+
+		if isTextValue(oldValue) || isTextValue(newValue) {
+			textDiffs = append(textDiffs, field)
+			continue
+		}
+
+		tableData = append(tableData, []string{field, oldValue, newValue})
+	}
+
+	if len(tableData) > 1 {
+		_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	}
+
+	for _, field := range textDiffs {
+		change := (*update.Fields)[field]
+		fmt.Println(field + ":")
+		printTextDiff(formatHistoryValue(change.OldValue), formatHistoryValue(change.NewValue))
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		fmt.Println("Links:")
+		for _, relation := range removed {
+			fmt.Println(pterm.FgRed.Sprint("  - " + formatHistoryRelation(relation)))
+		}
+		for _, relation := range added {
+			fmt.Println(pterm.FgGreen.Sprint("  + " + formatHistoryRelation(relation)))
+		}
+	}
+
+	return true
+}
+
+func printTextDiff(oldText, newText string) {
+	lines := diff.Lines(splitTextLines(oldText), splitTextLines(newText))
+	for _, line := range lines {
+		switch line.Op {
+		case diff.Delete:
+			fmt.Println(pterm.FgRed.Sprint("  - " + line.Text))
+		case diff.Insert:
+			fmt.Println(pterm.FgGreen.Sprint("  + " + line.Text))
+		default:
+			fmt.Println("    " + line.Text)
+		}
+	}
+}
+
+func splitTextLines(text string) []string {
+	if htmlTagRegexp.MatchString(text) {
+		text = prettyprint.HTMLText(text)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+func isTextValue(value string) bool {
+	return strings.Contains(value, "\n") || htmlTagRegexp.MatchString(value)
+}
+
+func formatHistoryValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		// identity fields
+		if displayName, ok := v["displayName"].(string); ok {
+			return displayName
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func formatHistoryRelation(relation workitemtracking.WorkItemRelation) string {
+	var rel, url string
+	if relation.Rel != nil {
+		rel = *relation.Rel
+	}
+	if relation.Url != nil {
+		url = *relation.Url
+	}
+	return rel + " " + url
+}
+
+// getUpdateDate returns date of the change, revised date of the latest revision is far in the future.
+func getUpdateDate(update *workitemtracking.WorkItemUpdate) time.Time {
+	if update.Fields != nil {
+		if change, ok := (*update.Fields)["System.ChangedDate"]; ok {
+			if value, ok := change.NewValue.(string); ok {
+				if changedDate, err := time.Parse(time.RFC3339, value); err == nil {
+					return changedDate
+				}
+			}
+		}
+	}
+
+	if update.RevisedDate != nil {
+		return update.RevisedDate.Time
+	}
+	return time.Time{}
+}
+
+// parseSince parses date or period relative to now like 7d or 12h.
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", time.RFC3339} {
+		if since, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return since, nil
+		}
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since value '%s'", value)
+	}
+	return time.Now().Add(-period), nil
+}
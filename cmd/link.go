@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	linkCmd = &cobra.Command{
+		Use:   "link <Work Item ID> <Link type> <Target Work Item ID, ...>",
+		Short: "Link work items",
+		Long: `Link work item with target work items.
+Link type is a name of server relation type like parent, child, related, affected-by, successor etc. (see 'tasker link-types').`,
+		Args: cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, targetIDs, err := parseLinkArgs(args)
+			cobra.CheckErr(err)
+
+			err = linkCommand(cmd.Context(), workItemID, args[1], targetIDs)
+			cobra.CheckErr(err)
+		},
+	}
+
+	unlinkCmd = &cobra.Command{
+		Use:   "unlink <Work Item ID> <Link type> <Target Work Item ID, ...>",
+		Short: "Unlink work items",
+		Long:  "Remove links of specified type between work item and target work items.",
+		Args:  cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, targetIDs, err := parseLinkArgs(args)
+			cobra.CheckErr(err)
+
+			err = unlinkCommand(cmd.Context(), workItemID, args[1], targetIDs)
+			cobra.CheckErr(err)
+		},
+	}
+
+	linksCmd = &cobra.Command{
+		Use:   "links <Work Item ID>",
+		Short: "List work item links",
+		Long:  "List work item links to other work items, commits, pull requests and hyperlinks.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			workItemID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = linksCommand(cmd.Context(), workItemID)
+			cobra.CheckErr(err)
+		},
+	}
+
+	linkTypesCmd = &cobra.Command{
+		Use:   "link-types",
+		Short: "List link types",
+		Long:  "List link types available on the server.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := linkTypesCommand(cmd.Context())
+			cobra.CheckErr(err)
+		},
+	}
+
+	linkCmdFlagComment string
+)
+
+func init() {
+	rootCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(unlinkCmd)
+	rootCmd.AddCommand(linksCmd)
+	rootCmd.AddCommand(linkTypesCmd)
+
+	linkCmd.Flags().StringVarP(&linkCmdFlagComment, "comment", "c", "", "Link comment")
+}
+
+func parseLinkArgs(args []string) (int, []int, error) {
+	workItemID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var targetIDs []int
+	for _, arg := range args[2:] {
+		targetID, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, nil, err
+		}
+		targetIDs = append(targetIDs, targetID)
+	}
+
+	return workItemID, targetIDs, nil
+}
+
+func findLinkType(ctx context.Context, a *tfs.API, name string) (workitem.LinkType, error) {
+	linkTypes, err := a.WiClient.GetLinkTypes(ctx)
+	if err != nil {
+		return workitem.LinkType{}, err
+	}
+
+	linkType, err := workitem.FindLinkType(linkTypes, name)
+	if err != nil {
+		return linkType, err
+	}
+
+	if !linkType.WorkItemLink {
+		return linkType, fmt.Errorf("'%s' is not a link between work items", name)
+	}
+
+	return linkType, nil
+}
+
+func linkCommand(ctx context.Context, workItemID int, linkTypeName string, targetIDs []int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	linkType, err := findLinkType(ctx, a, linkTypeName)
+	if err != nil {
+		return err
+	}
+
+	err = a.WiClient.AddLinks(ctx, workItemID, linkType.ReferenceName, targetIDs, linkCmdFlagComment)
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Println(fmt.Sprintf("LINKED %d %s %v", workItemID, linkType.Name, targetIDs))
+	return nil
+}
+
+func unlinkCommand(ctx context.Context, workItemID int, linkTypeName string, targetIDs []int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	linkType, err := findLinkType(ctx, a, linkTypeName)
+	if err != nil {
+		return err
+	}
+
+	err = a.WiClient.RemoveLinks(ctx, workItemID, linkType.ReferenceName, targetIDs)
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Println(fmt.Sprintf("UNLINKED %d %s %v", workItemID, linkType.Name, targetIDs))
+	return nil
+}
+
+func linksCommand(ctx context.Context, workItemID int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	linkTypes, err := a.WiClient.GetLinkTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	w, err := a.WiClient.GetExpanded(ctx, workItemID)
+	if err != nil {
+		return err
+	}
+
+	links := workitem.GetLinks(w, linkTypes)
+	if len(links) == 0 {
+		fmt.Println("no links")
+		return nil
+	}
+
+	linkedIDs := lo.FilterMap(links, func(link workitem.Link, _ int) (int, bool) {
+		return link.WorkItemID, link.WorkItemID > 0
+	})
+
+	titles := make(map[int]string)
+	if len(linkedIDs) > 0 {
+		linked, err := a.WiClient.GetList(ctx, lo.Uniq(linkedIDs))
+		if err != nil {
+			return err
+		}
+		for i := range linked {
+			titles[*linked[i].Id] = fmt.Sprintf("[%s] %s", workitem.GetState(&linked[i]), workitem.GetTitle(&linked[i]))
+		}
+	}
+
+	tableData := [][]string{{"Type", "Target", "Title", "Comment"}}
+	for _, link := range links {
+		target := link.URL
+		title := ""
+		switch {
+		case link.WorkItemID > 0:
+			target = strconv.Itoa(link.WorkItemID)
+			title = titles[link.WorkItemID]
+		case link.Artifact != "":
+			target = link.Artifact
+		}
+
+		tableData = append(tableData, []string{link.Type.Name, target, title, link.Comment})
+	}
+
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
+
+func linkTypesCommand(ctx context.Context) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	linkTypes, err := a.WiClient.GetLinkTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	tableData := [][]string{{"Name", "Display name", "Reference name"}}
+	for _, linkType := range linkTypes {
+		if linkType.WorkItemLink {
+			tableData = append(tableData, []string{linkType.Name, linkType.DisplayName, linkType.ReferenceName})
+		}
+	}
+
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"tasker/wiki"
+	"tasker/wiki/macros"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	wikiMacros     *macros.Templates
+	wikiMacrosErr  error
+	wikiMacrosOnce sync.Once
+)
+
+// getWikiMacros returns macro templates configured by 'wikiMacros' key, 'syncCmdTfsTaskMacroPath' is still
+// honoured for the task macro.
+func getWikiMacros() (*macros.Templates, error) {
+	wikiMacrosOnce.Do(func() {
+		config := make(map[string]macros.Template)
+		wikiMacrosErr = viper.UnmarshalKey("wikiMacros", &config)
+		if wikiMacrosErr != nil {
+			return
+		}
+
+		if _, ok := config[string(macros.Task)]; !ok {
+			if path := viper.GetString("syncCmdTfsTaskMacroPath"); strings.TrimSpace(path) != "" {
+				config[string(macros.Task)] = macros.Template{Path: path}
+			}
+		}
+
+		wikiMacros, wikiMacrosErr = macros.New(config)
+	})
+	return wikiMacros, wikiMacrosErr
+}
+
+// getWikiParseMacros returns the option recognizing work item macros of configured templates,
+// nil (built-in templates) if templates are invalid.
+func getWikiParseMacros() wiki.ParseOption {
+	templates, err := getWikiMacros()
+	if err != nil {
+		return nil
+	}
+	return wiki.WorkItemMacros(templates)
+}
+
+// renderWikiMacro renders the macro by configured template, invalid template is fatal.
+func renderWikiMacro(kind macros.Kind, data macros.Data) string {
+	templates, err := getWikiMacros()
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+
+	macro, err := templates.Render(kind, data)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	return macro
+}
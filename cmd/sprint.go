@@ -0,0 +1,653 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tasker/ptr"
+	"tasker/tasksui"
+	"tasker/tfs"
+	"tasker/tfs/work"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+
+	azurework "github.com/microsoft/azure-devops-go-api/azuredevops/v6/work"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const unassignedName = "Unassigned"
+
+var (
+	sprintCmd = &cobra.Command{
+		Use:   "sprint",
+		Short: "Manage sprints",
+		Long:  `View sprint reports, roll over unfinished tasks etc.`,
+	}
+
+	sprintReportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Sprint capacity report",
+		Long:  `Show team capacity vs. planned estimates per person and per discipline and the sprint burndown.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := sprintReportCommand(cmd.Context())
+			cobra.CheckErr(err)
+		},
+	}
+
+	sprintRolloverCmd = &cobra.Command{
+		Use:   "rollover",
+		Short: "Roll over unfinished tasks",
+		Long: `Move unfinished tasks of the sprint to the next sprint.
+With --close flag unfinished tasks are closed and their continuation copies with remaining estimate are created in the next sprint.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := sprintRolloverCommand(cmd.Context())
+			cobra.CheckErr(err)
+		},
+	}
+
+	sprintReportCmdFlagIteration   string
+	sprintReportCmdFlagNoBurndown  bool
+	sprintReportCmdFlagWikiPageID  uint
+	sprintReportCmdFlagPrintMarkup bool
+
+	sprintRolloverCmdFlagIteration string
+	sprintRolloverCmdFlagPrevious  bool
+	sprintRolloverCmdFlagTarget    string
+	sprintRolloverCmdFlagClose     bool
+)
+
+func init() {
+	rootCmd.AddCommand(sprintCmd)
+	sprintCmd.AddCommand(sprintReportCmd)
+	sprintCmd.AddCommand(sprintRolloverCmd)
+
+	sprintReportCmd.Flags().StringVarP(&sprintReportCmdFlagIteration, "iteration", "i", "", "Iteration name or path (current iteration if not specified)")
+	sprintReportCmd.Flags().BoolVarP(&sprintReportCmdFlagNoBurndown, "no-burndown", "", false, "Do not calculate burndown (saves requests of work item revisions)")
+	sprintReportCmd.Flags().UintVarP(&sprintReportCmdFlagWikiPageID, "wiki-page", "w", 0, "ID of Wiki page to publish the report to")
+	sprintReportCmd.Flags().BoolVarP(&sprintReportCmdFlagPrintMarkup, "storage", "", false, "Print report as Confluence storage markup")
+
+	sprintRolloverCmd.Flags().StringVarP(&sprintRolloverCmdFlagIteration, "iteration", "i", "", "Iteration name or path with unfinished tasks (current iteration if not specified)")
+	sprintRolloverCmd.Flags().BoolVarP(&sprintRolloverCmdFlagPrevious, "previous", "", false, "Roll over unfinished tasks of the previous iteration")
+	sprintRolloverCmd.Flags().StringVarP(&sprintRolloverCmdFlagTarget, "target", "t", "", "Target iteration name or path (next iteration if not specified)")
+	sprintRolloverCmd.Flags().BoolVarP(&sprintRolloverCmdFlagClose, "close", "", false, "Close unfinished tasks and create continuation copies instead of moving")
+	sprintRolloverCmd.MarkFlagsMutuallyExclusive("iteration", "previous")
+}
+
+type sprintLoad struct {
+	Name      string
+	Available float32
+	Planned   float32
+	Remaining float32
+}
+
+type sprintBurndownPoint struct {
+	Date      time.Time
+	Remaining float32
+	Ideal     float32
+}
+
+type sprintReport struct {
+	Iteration   *azurework.TeamSettingsIteration
+	WorkingDays []time.Time
+	People      []*sprintLoad
+	Disciplines []*sprintLoad
+	Burndown    []sprintBurndownPoint
+}
+
+func sprintReportCommand(ctx context.Context) error {
+	spinner, _ := pterm.DefaultSpinner.WithRemoveWhenDone().Start("Collecting sprint data...")
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		_ = spinner.Stop()
+		return err
+	}
+
+	report, err := buildSprintReport(ctx, a, sprintReportCmdFlagIteration)
+	_ = spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	if sprintReportCmdFlagPrintMarkup {
+		fmt.Println(renderSprintReportStorage(report))
+	} else {
+		printSprintReport(report)
+	}
+
+	if sprintReportCmdFlagWikiPageID != 0 {
+		wikiAPI, err := wiki.NewClient()
+		if err != nil {
+			return err
+		}
+
+		err = wiki.UploadContent(wikiAPI, strconv.Itoa(int(sprintReportCmdFlagWikiPageID)), renderSprintReportStorage(report), "storage")
+		if err != nil {
+			return err
+		}
+		pterm.Success.Println("Wiki page updated")
+	}
+
+	return nil
+}
+
+func buildSprintReport(ctx context.Context, a *tfs.API, iterationName string) (*sprintReport, error) {
+	iterations, err := work.GetIterations(ctx, a.Conn, a.Project, a.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	iteration := work.FindIteration(iterations, iterationName)
+	if iteration == nil {
+		return nil, errors.New("iteration not found")
+	}
+
+	settings, err := work.GetTeamSettings(ctx, a.Conn, a.Project, a.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	var workingDaysNames []string
+	if settings.WorkingDays != nil {
+		workingDaysNames = *settings.WorkingDays
+	}
+
+	teamDaysOff, err := work.GetTeamDaysOff(ctx, a.Conn, a.Project, a.Team, *iteration.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	capacities, err := work.GetCapacities(ctx, a.Conn, a.Project, a.Team, *iteration.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := getIterationTasks(ctx, a, *iteration.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &sprintReport{
+		Iteration:   iteration,
+		WorkingDays: work.WorkingDays(iteration, workingDaysNames, teamDaysOff),
+	}
+
+	people := make(map[string]*sprintLoad)
+	disciplines := make(map[string]*sprintLoad)
+	getLoad := func(m map[string]*sprintLoad, name string) *sprintLoad {
+		if name == "" {
+			name = unassignedName
+		}
+		load, ok := m[name]
+		if !ok {
+			load = &sprintLoad{Name: name}
+			m[name] = load
+		}
+		return load
+	}
+
+	for _, capacity := range *capacities {
+		if capacity.TeamMember == nil || capacity.TeamMember.DisplayName == nil {
+			continue
+		}
+
+		var memberDaysOff []azurework.DateRange
+		if capacity.DaysOff != nil {
+			memberDaysOff = *capacity.DaysOff
+		}
+		days := float32(len(work.WorkingDays(iteration, workingDaysNames, teamDaysOff, memberDaysOff)))
+
+		person := getLoad(people, *capacity.TeamMember.DisplayName)
+		if capacity.Activities == nil {
+			continue
+		}
+		for _, activity := range *capacity.Activities {
+			if activity.CapacityPerDay == nil {
+				continue
+			}
+			available := *activity.CapacityPerDay * days
+			person.Available += available
+
+			var activityName string
+			if activity.Name != nil {
+				activityName = *activity.Name
+			}
+			getLoad(disciplines, activityName).Available += available
+		}
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		for _, load := range []*sprintLoad{getLoad(people, workitem.GetAssignedTo(task)), getLoad(disciplines, workitem.GetDiscipline(task))} {
+			load.Planned += workitem.GetOriginalEstimate(task)
+			if !workitem.IsCompleted(task) {
+				load.Remaining += workitem.GetRemainingWork(task)
+			}
+		}
+	}
+
+	report.People = sortedSprintLoads(people)
+	report.Disciplines = sortedSprintLoads(disciplines)
+
+	if !sprintReportCmdFlagNoBurndown {
+		report.Burndown, err = calculateBurndown(ctx, a, tasks, *iteration.Path, report.WorkingDays)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func getIterationTasks(ctx context.Context, a *tfs.API, iterationPath string) ([]workitemtracking.WorkItem, error) {
+	ids, err := a.WiClient.QueryIDs(ctx, `
+		SELECT [Id]
+		FROM WorkItems
+		WHERE [Work Item Type] = 'Task'
+			AND [System.IterationPath] = '`+iterationPath+`'
+			AND [State] <> 'Removed'
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return a.WiClient.GetList(ctx, ids)
+}
+
+func sortedSprintLoads(m map[string]*sprintLoad) []*sprintLoad {
+	loads := make([]*sprintLoad, 0, len(m))
+	for _, load := range m {
+		loads = append(loads, load)
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		return loads[i].Name < loads[j].Name
+	})
+	return loads
+}
+
+func calculateBurndown(ctx context.Context, a *tfs.API, tasks []workitemtracking.WorkItem, iterationPath string, days []time.Time) ([]sprintBurndownPoint, error) {
+	if len(days) == 0 {
+		return nil, nil
+	}
+
+	revisions := make([][]workitemtracking.WorkItem, len(tasks))
+	wg, ctx := errgroup.WithContext(ctx)
+	var m sync.Mutex
+	guard := make(chan struct{}, 10)
+	for i := range tasks {
+		index := i
+		taskID := *tasks[i].Id
+		wg.Go(func() error {
+			guard <- struct{}{}
+			defer func() {
+				<-guard
+			}()
+
+			taskRevisions, err := a.WiClient.GetAllRevisions(ctx, taskID)
+			if err != nil {
+				return err
+			}
+
+			m.Lock()
+			revisions[index] = taskRevisions
+			m.Unlock()
+
+			return nil
+		})
+	}
+
+	err := wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now()
+	var points []sprintBurndownPoint
+	for _, day := range days {
+		if day.After(today) {
+			break
+		}
+
+		endOfDay := day.AddDate(0, 0, 1)
+		var remaining float32
+		for _, taskRevisions := range revisions {
+			revision := revisionAt(taskRevisions, endOfDay)
+			if revision == nil || workitem.IsCompleted(revision) || workitem.GetIterationPath(revision) != iterationPath {
+				continue
+			}
+			remaining += workitem.GetRemainingWork(revision)
+		}
+
+		points = append(points, sprintBurndownPoint{
+			Date:      day,
+			Remaining: remaining,
+		})
+	}
+
+	if len(points) > 0 {
+		total := points[0].Remaining
+		for i := range points {
+			if len(days) > 1 {
+				points[i].Ideal = total - total*float32(i)/float32(len(days)-1)
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// revisionAt returns the last revision changed before the moment.
+func revisionAt(revisions []workitemtracking.WorkItem, moment time.Time) *workitemtracking.WorkItem {
+	var result *workitemtracking.WorkItem
+	for i := range revisions {
+		if workitem.GetChangedDate(&revisions[i]).Before(moment) {
+			result = &revisions[i]
+		}
+	}
+	return result
+}
+
+func printSprintReport(report *sprintReport) {
+	pterm.DefaultSection.Printfln("%s (working days: %d)", *report.Iteration.Path, len(report.WorkingDays))
+
+	pterm.DefaultSection.WithLevel(2).Println("Capacity by person")
+	printSprintLoads(report.People)
+
+	pterm.DefaultSection.WithLevel(2).Println("Capacity by discipline")
+	printSprintLoads(report.Disciplines)
+
+	if len(report.Burndown) > 0 {
+		pterm.DefaultSection.WithLevel(2).Println("Burndown")
+		bars := make(pterm.Bars, 0, len(report.Burndown))
+		for _, point := range report.Burndown {
+			style := pterm.NewStyle(pterm.FgGreen)
+			if point.Remaining > point.Ideal {
+				style = pterm.NewStyle(pterm.FgRed)
+			}
+			bars = append(bars, pterm.Bar{
+				Label: fmt.Sprintf("%s (ideal %.0f)", point.Date.Format(time.DateOnly), point.Ideal),
+				Value: int(point.Remaining),
+				Style: style,
+			})
+		}
+		_ = pterm.DefaultBarChart.WithHorizontal().WithShowValue().WithBars(bars).Render()
+	}
+}
+
+func printSprintLoads(loads []*sprintLoad) {
+	tableData := [][]string{{"Name", "Available", "Planned", "Remaining", "Balance"}}
+
+	var total sprintLoad
+	for _, load := range loads {
+		total.Available += load.Available
+		total.Planned += load.Planned
+		total.Remaining += load.Remaining
+
+		balance := fmt.Sprintf("%v", load.Available-load.Remaining)
+		if load.Remaining > load.Available {
+			balance = pterm.Red(balance)
+		}
+
+		tableData = append(tableData, []string{
+			load.Name,
+			fmt.Sprintf("%v", load.Available),
+			fmt.Sprintf("%v", load.Planned),
+			fmt.Sprintf("%v", load.Remaining),
+			balance,
+		})
+	}
+
+	tableData = append(tableData, []string{
+		"∑",
+		fmt.Sprintf("%v", total.Available),
+		fmt.Sprintf("%v", total.Planned),
+		fmt.Sprintf("%v", total.Remaining),
+		fmt.Sprintf("%v", total.Available-total.Remaining),
+	})
+
+	_ = pterm.DefaultTable.
+		WithHasHeader().
+		WithData(tableData).
+		Render()
+}
+
+func renderSprintReportStorage(report *sprintReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("<h1>" + html.EscapeString(*report.Iteration.Path) + "</h1>")
+	sb.WriteString(fmt.Sprintf("<p>Working days: %d</p>", len(report.WorkingDays)))
+
+	sb.WriteString("<h2>Capacity by person</h2>")
+	renderSprintLoadsStorage(&sb, report.People)
+
+	sb.WriteString("<h2>Capacity by discipline</h2>")
+	renderSprintLoadsStorage(&sb, report.Disciplines)
+
+	if len(report.Burndown) > 0 {
+		sb.WriteString("<h2>Burndown</h2>")
+		sb.WriteString(`<ac:structured-macro ac:name="chart" ac:schema-version="1">`)
+		sb.WriteString(`<ac:parameter ac:name="type">line</ac:parameter>`)
+		sb.WriteString(`<ac:parameter ac:name="dataOrientation">vertical</ac:parameter>`)
+		sb.WriteString(`<ac:parameter ac:name="title">Burndown</ac:parameter>`)
+		sb.WriteString(`<ac:rich-text-body><table><tbody><tr><th>Date</th><th>Remaining</th><th>Ideal</th></tr>`)
+		for _, point := range report.Burndown {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%v</td><td>%.1f</td></tr>", point.Date.Format(time.DateOnly), point.Remaining, point.Ideal))
+		}
+		sb.WriteString(`</tbody></table></ac:rich-text-body></ac:structured-macro>`)
+	}
+
+	return sb.String()
+}
+
+func renderSprintLoadsStorage(sb *strings.Builder, loads []*sprintLoad) {
+	sb.WriteString("<table><tbody><tr><th>Name</th><th>Available</th><th>Planned</th><th>Remaining</th><th>Balance</th></tr>")
+	for _, load := range loads {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td></tr>",
+			html.EscapeString(load.Name), load.Available, load.Planned, load.Remaining, load.Available-load.Remaining))
+	}
+	sb.WriteString("</tbody></table>")
+}
+
+type rolloverTask struct {
+	source    *workitemtracking.WorkItem
+	title     string
+	remaining float32
+}
+
+func (t *rolloverTask) GetTitle() string             { return t.title }
+func (t *rolloverTask) SetTitle(title string)        { t.title = title }
+func (t *rolloverTask) GetDescription() string       { return workitem.GetDescription(t.source) }
+func (t *rolloverTask) SetDescription(_ string)      { /* not supported */ }
+func (t *rolloverTask) GetEstimate() float32         { return t.remaining }
+func (t *rolloverTask) SetEstimate(estimate float32) { t.remaining = estimate }
+func (t *rolloverTask) GetTfsTaskID() int            { return *t.source.Id }
+func (t *rolloverTask) SetTfsTaskID(_ int)           { /* not supported */ }
+func (t *rolloverTask) GetTags() []string            { return workitem.GetTags(t.source) }
+func (t *rolloverTask) SetTags(_ []string)           { /* not supported */ }
+func (t *rolloverTask) GetTagsString() string        { return strings.Join(t.GetTags(), "; ") }
+func (t *rolloverTask) SetTagsString(_ string)       { /* not supported */ }
+func (t *rolloverTask) GetAssignedTo() string        { return workitem.GetAssignedTo(t.source) }
+func (t *rolloverTask) Clone() tasksui.Task {
+	t2 := *t
+	return &t2
+}
+
+type rolloverTable struct {
+	tasks []*rolloverTask
+}
+
+func (t *rolloverTable) GetTasks() []tasksui.Task {
+	return lo.Map(t.tasks, func(tsk *rolloverTask, _ int) tasksui.Task { return tsk })
+}
+func (t *rolloverTable) SetTask(tsk tasksui.Task, index int) {
+	t.tasks[index] = tsk.(*rolloverTask)
+}
+
+func sprintRolloverCommand(ctx context.Context) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	iterations, err := work.GetIterations(ctx, a.Conn, a.Project, a.Team)
+	if err != nil {
+		return err
+	}
+
+	var source *azurework.TeamSettingsIteration
+	if sprintRolloverCmdFlagPrevious {
+		source = work.FindPreviousIteration(iterations)
+	} else {
+		source = work.FindIteration(iterations, sprintRolloverCmdFlagIteration)
+	}
+	if source == nil {
+		return errors.New("source iteration not found")
+	}
+
+	var target *azurework.TeamSettingsIteration
+	if sprintRolloverCmdFlagTarget != "" {
+		target = work.FindIteration(iterations, sprintRolloverCmdFlagTarget)
+	} else {
+		target = work.FindNextIteration(iterations, source)
+	}
+	if target == nil {
+		return errors.New("target iteration not found")
+	}
+
+	ids, err := a.WiClient.QueryIDs(ctx, `
+		SELECT [Id]
+		FROM WorkItems
+		WHERE [Work Item Type] = 'Task'
+			AND [System.IterationPath] = '`+*source.Path+`'
+			AND [State] NOT IN ('Closed', 'Resolved', 'Removed')
+	`)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		fmt.Println("nothing to roll over")
+		return nil
+	}
+
+	workItems, err := a.WiClient.GetListWithRelations(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	table := &rolloverTable{}
+	for i := range workItems {
+		table.tasks = append(table.tasks, &rolloverTask{
+			source:    &workItems[i],
+			title:     workitem.GetTitle(&workItems[i]),
+			remaining: workitem.GetRemainingWork(&workItems[i]),
+		})
+	}
+
+	pterm.Info.Printfln("%s → %s", *source.Path, *target.Path)
+
+	ok, err := tasksui.PreviewTasks([]tasksui.Table{table})
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return rolloverTasks(ctx, a, table.tasks, *target.Path)
+}
+
+func rolloverTasks(ctx context.Context, a *tfs.API, tasks []*rolloverTask, iterationPath string) error {
+	progressbar, err := pterm.DefaultProgressbar.WithTitle("Processing...").WithTotal(len(tasks)).WithRemoveWhenDone().Start()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		progressbar.UpdateTitle(fmt.Sprintf("Processing %s", cutString(t.title, 20, true)))
+
+		if sprintRolloverCmdFlagClose {
+			continuation, err := continueTask(ctx, a, t, iterationPath)
+			if err == nil {
+				pterm.Success.Println(fmt.Sprintf("CONTINUED %d as %d %s", *t.source.Id, *continuation.Id, t.title))
+			} else {
+				pterm.Error.Println(fmt.Sprintf("NOT CONTINUED %d %s: %s", *t.source.Id, t.title, err.Error()))
+			}
+		} else {
+			err := a.WiClient.UpdateFields(ctx, *t.source.Id, []*workitem.Field{
+				{Path: ptr.FromStr("/fields/System.IterationPath"), Value: iterationPath},
+				{Path: ptr.FromStr("/fields/System.Title"), Value: t.title},
+				{Path: ptr.FromStr("/fields/Microsoft.VSTS.Scheduling.RemainingWork"), Value: t.remaining},
+			})
+			if err == nil {
+				pterm.Success.Println(fmt.Sprintf("MOVED %d %s", *t.source.Id, t.title))
+			} else {
+				pterm.Error.Println(fmt.Sprintf("NOT MOVED %d %s: %s", *t.source.Id, t.title, err.Error()))
+			}
+		}
+
+		progressbar.Increment()
+	}
+	_, _ = progressbar.Stop()
+
+	return nil
+}
+
+func continueTask(ctx context.Context, a *tfs.API, t *rolloverTask, iterationPath string) (*workitemtracking.WorkItem, error) {
+	relations := []*workitem.Relation{
+		{
+			URL:  *t.source.Url,
+			Type: workitem.RelatedLinkType,
+		},
+	}
+
+	if parentURL := workitem.GetParentURL(t.source); parentURL != "" {
+		relations = append(relations, &workitem.Relation{
+			URL:  parentURL,
+			Type: workitem.ParentLinkType,
+		})
+	}
+
+	fields := []*workitem.Field{
+		{Path: ptr.FromStr("/fields/System.Title"), Value: t.title},
+		{Path: ptr.FromStr("/fields/Microsoft.VSTS.Scheduling.OriginalEstimate"), Value: t.remaining},
+		{Path: ptr.FromStr("/fields/Microsoft.VSTS.Scheduling.RemainingWork"), Value: t.remaining},
+	}
+
+	if assignedTo := workitem.GetAssignedTo(t.source); assignedTo != "" {
+		fields = append(fields, &workitem.Field{Path: ptr.FromStr("/fields/System.AssignedTo"), Value: assignedTo})
+	}
+
+	continuation, err := a.WiClient.CopyWithFields(ctx, t.source, workitem.GetAreaPath(t.source), iterationPath, relations, workitem.GetTags(t.source), fields)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.WiClient.UpdateFields(ctx, *t.source.Id, []*workitem.Field{
+		{Path: ptr.FromStr("/fields/System.State"), Value: "Closed"},
+	})
+	if err != nil {
+		// the continuation exists, so it must not be created again by the next rollover
+		return nil, fmt.Errorf("continuation %d created, but the task is not closed, close it manually: %w", *continuation.Id, err)
+	}
+
+	return continuation, nil
+}
@@ -1,23 +1,23 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"html"
 	"regexp"
 	"strconv"
 	"strings"
-	"text/template"
+	"time"
 	"unicode/utf8"
 
 	"tasker/tasksui"
 	"tasker/tfs"
+	"tasker/tfs/workitem"
 	"tasker/wiki"
+	"tasker/wiki/macros"
 
 	"github.com/eiannone/keyboard"
-	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
 	"github.com/pterm/pterm"
 	"github.com/samber/lo"
@@ -29,11 +29,24 @@ import (
 var (
 	// syncCmd represents the sync command
 	syncCmd = &cobra.Command{
-		Use:   "sync <Wiki page ID>",
+		Use:   "sync [Wiki page ID]",
 		Short: "Syncs wiki with tfs",
-		Long:  `Creates or updates tasks from wiki page in TFS and inserts tfs-macros into wiki page`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Creates or updates tasks from wiki page in TFS and inserts tfs-macros into wiki page.
+Several pages can be synced at once by selecting them with --label and --parent instead of page ID,
+--space narrows selected pages.`,
+		Args: cobra.RangeArgs(0, 1),
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(syncCmdFlagLabels) > 0 || syncCmdFlagParent != "" {
+				cobra.CheckErr(cobra.NoArgs(cmd, args))
+				err := syncPagesCommand(cmd.Context())
+				cobra.CheckErr(err)
+				return
+			}
+
+			cobra.CheckErr(cobra.ExactArgs(1)(cmd, args))
+			if syncCmdFlagSpace != "" {
+				cobra.CheckErr(errors.New("--space selects several pages with --label or --parent, it can not be used with page ID"))
+			}
 			wikiPageID, err := strconv.Atoi(args[0])
 			cobra.CheckErr(err)
 
@@ -53,10 +66,23 @@ var (
 	syncCmdFlagTags              []string
 	syncCmdFlagPartNumber        uint32
 	syncCmdFlagAppendTagsToTitle bool
-
-	syncCmdTemplatesCache = make(map[string]*template.Template)
+	syncCmdFlagComment           bool
+	syncCmdFlagWatch             bool
+	syncCmdFlagWatchInterval     time.Duration
+	syncCmdFlagWatchMaxCreate    int
+	syncCmdFlagWatchWorkItems    bool
+	syncCmdFlagLabels            []string
+	syncCmdFlagSpace             string
+	syncCmdFlagParent            string
+	syncCmdFlagConcurrency       int
+	syncCmdFlagVerbose           bool
+	syncCmdFlagDiff              bool
+	syncCmdFlagDiffFrom          int
 )
 
+// syncVersionMessage is the message of wiki page versions saved by sync, --diff compares with the last of them.
+const syncVersionMessage = "tasker sync"
+
 func init() {
 	rootCmd.AddCommand(syncCmd)
 
@@ -68,44 +94,228 @@ func init() {
 	syncCmd.Flags().StringSliceVarP(&syncCmdFlagTags, "tag", "t", []string{"разработка"}, "Tags of the tasks. Can be separated by comma or specified multiple times.")
 	syncCmd.Flags().Uint32VarP(&syncCmdFlagPartNumber, "part", "p", 0, "Table number (tasks part), if tasks splitted into multiple tables (parts)")
 	syncCmd.Flags().BoolVar(&syncCmdFlagAppendTagsToTitle, "append-tags-to-title", false, "Append tas tags to task title")
+	syncCmd.Flags().BoolVar(&syncCmdFlagComment, "comment", false, "Post comment with link to wiki page into created and updated tasks")
+	syncCmd.Flags().BoolVarP(&syncCmdFlagWatch, "watch", "w", false, "Watch wiki page and sync its changes without preview")
+	syncCmd.Flags().DurationVar(&syncCmdFlagWatchInterval, "interval", 30*time.Second, "Wiki page polling interval in watch mode")
+	syncCmd.Flags().IntVar(&syncCmdFlagWatchMaxCreate, "max-create", 10, "Max count of tasks created per cycle in watch mode")
+	syncCmd.Flags().BoolVar(&syncCmdFlagWatchWorkItems, "watch-tfs", false, "Sync also when tasks are changed in TFS in watch mode (wiki page wins)")
+	syncCmd.Flags().StringArrayVarP(&syncCmdFlagLabels, "label", "l", nil, "Sync all pages with the label")
+	syncCmd.Flags().StringVarP(&syncCmdFlagSpace, "space", "s", "", "Space key of pages selected by --label or --parent")
+	syncCmd.Flags().StringVar(&syncCmdFlagParent, "parent", "", "Sync all child pages of the page (ID or title)")
+	syncCmd.Flags().IntVar(&syncCmdFlagConcurrency, "concurrency", 4, "Max count of pages synced concurrently")
+	syncCmd.Flags().BoolVarP(&syncCmdFlagVerbose, "verbose", "v", false, "Report which tables of the page are detected as tasks tables and why")
+	syncCmd.Flags().BoolVar(&syncCmdFlagDiff, "diff", false, "Mark tasks added and changed since the version saved by the last sync in preview and show removed ones (single page only)")
+	syncCmd.Flags().IntVar(&syncCmdFlagDiffFrom, "from", 0, "Version of the page compared by --diff (default the version saved by the last sync or the previous one)")
 }
 
 func syncCommand(ctx context.Context, wikiPageID int) error {
+	if syncCmdFlagWatch {
+		return watchSyncCommand(ctx, wikiPageID)
+	}
+
 	api, err := wiki.NewClient()
 	if err != nil {
 		return err
 	}
 
-	content, err := api.GetContentByID(strconv.Itoa(wikiPageID), goconfluence.ContentQuery{
-		Expand: []string{
-			"body.storage",
-			"space",
-			"version",
-		},
-	})
+	content, err := getPageWithBody(api, strconv.Itoa(wikiPageID))
+	if err != nil {
+		return err
+	}
 
+	featureID, err := getSyncFeatureID(content)
 	if err != nil {
 		return err
 	}
 
-	if syncCmdFlagFeatureWorkItemID <= 0 {
-		id, err := strconv.ParseUint(featureIDRegexp.FindString(content.Title), 10, 32)
+	tasks, err := parseTasksTable(content, syncCmdFlagVerbose)
+	if err != nil {
+		return err
+	}
+
+	var changes map[*wiki.Task]string
+	if syncCmdFlagDiff {
+		// changes are found before titles are prefixed, since prefixes of following rows change when a row is inserted
+		changes, err = getSyncChanges(api, content, tasks)
 		if err != nil {
-			return errors.New("unable to determine TFS feature ID from wiki page title")
+			return err
 		}
-		syncCmdFlagFeatureWorkItemID = uint32(id)
 	}
 
-	tasks, err := wiki.ParseTasksTable(content.Body.Storage.Value)
+	tasks, err = prepareSyncTasks(tasks)
 	if err != nil {
 		return err
 	}
 
-	tables, err := wiki.GroupByTable(tasks)
+	if len(tasks) == 0 {
+		fmt.Println("nothing to create or update")
+		return nil
+	}
+
+	// remove empty and not selected tables by grouping remained tasks again
+	tables, _ := wiki.GroupByTable(tasks)
+
+	uiTables := lo.Map(tables, func(tbl *wiki.Table, _ int) tasksui.Table {
+		if syncCmdFlagDiff {
+			return newSyncDiffTable(tbl, changes)
+		}
+		return tbl
+	})
+
+	ok, err := tasksui.PreviewTasks(uiTables)
 	if err != nil {
 		return err
 	}
 
+	if ok {
+		var comment string
+		if syncCmdFlagComment {
+			comment = createSyncComment(content)
+		}
+
+		err = createTasks(ctx, featureID, tasks, newDescriptionConverter(api, content), comment)
+		if err != nil {
+			return err
+		}
+
+		_, err = updateWikiPage(api, content, tasks)
+	}
+
+	return err
+}
+
+// getSyncChanges returns marks of the tasks added ("+") and changed ("~") since the compared version of the wiki page
+// and prints removed tasks, which are not shown in preview.
+func getSyncChanges(api *wiki.API, content *goconfluence.Content, tasks []*wiki.Task) (map[*wiki.Task]string, error) {
+	version, err := getSyncDiffVersion(api, content)
+	if err != nil || version == 0 {
+		return nil, err
+	}
+
+	pterm.DefaultSection.Printfln("Changes since version %d", version)
+	if version == content.Version.Number {
+		pterm.Info.Println("tasks not changed")
+		return nil, nil
+	}
+
+	previous, err := api.GetPageVersion(content.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	oldTasks, err := parseTasksTable(previous, false)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[*wiki.Task]string)
+	var removed []wiki.TaskDiff
+	for _, d := range wiki.DiffTasks(oldTasks, tasks) {
+		switch d.Kind {
+		case wiki.TaskAdded:
+			changes[d.New] = "+"
+		case wiki.TaskChanged:
+			changes[d.New] = "~"
+		case wiki.TaskRemoved:
+			removed = append(removed, d)
+		}
+	}
+
+	if len(changes) > 0 {
+		pterm.Info.Println(fmt.Sprintf("%d tasks added or changed are marked in preview with + and ~", len(changes)))
+	}
+	if len(removed) > 0 || len(changes) == 0 {
+		printTasksDiff(removed)
+	}
+	return changes, nil
+}
+
+// getSyncDiffVersion returns the version of the wiki page compared by --diff: the version specified by --from,
+// the version saved by the last sync or the previous one, 0 if the page has no previous version.
+func getSyncDiffVersion(api *wiki.API, content *goconfluence.Content) (int, error) {
+	current := content.Version.Number
+	if syncCmdFlagDiffFrom > 0 {
+		if syncCmdFlagDiffFrom >= current {
+			return 0, fmt.Errorf("invalid version to compare: %d, the page has %d versions", syncCmdFlagDiffFrom, current)
+		}
+		return syncCmdFlagDiffFrom, nil
+	}
+
+	version, err := api.GetLastVersionWithMessage(content.ID, syncVersionMessage)
+	if err != nil {
+		return 0, err
+	}
+	if version > 0 {
+		return version, nil
+	}
+
+	if current < 2 {
+		pterm.Info.Println("page has no previous version")
+		return 0, nil
+	}
+	return current - 1, nil
+}
+
+// syncDiffTask is a task previewed with the mark of its change since the compared version of the wiki page.
+type syncDiffTask struct {
+	*wiki.Task
+	change string
+}
+
+func (t *syncDiffTask) GetChange() string { return t.change }
+func (t *syncDiffTask) Clone() tasksui.Task {
+	return &syncDiffTask{Task: t.Task.Clone().(*wiki.Task), change: t.change}
+}
+
+// syncDiffTable is a tasks table previewed with marks of changed tasks.
+type syncDiffTable struct {
+	*wiki.Table
+	changes []string
+}
+
+func newSyncDiffTable(table *wiki.Table, changes map[*wiki.Task]string) *syncDiffTable {
+	return &syncDiffTable{
+		Table:   table,
+		changes: lo.Map(table.Tasks, func(t *wiki.Task, _ int) string { return changes[t] }),
+	}
+}
+
+func (t *syncDiffTable) GetTasks() []tasksui.Task {
+	return lo.Map(t.Tasks, func(tsk *wiki.Task, i int) tasksui.Task { return &syncDiffTask{Task: tsk, change: t.changes[i]} })
+}
+func (t *syncDiffTable) SetTask(tsk tasksui.Task, index int) {
+	t.Table.SetTask(tsk.(*syncDiffTask).Task, index)
+}
+
+// getSyncFeatureID returns feature ID specified by flag or determined from the wiki page title.
+func getSyncFeatureID(content *goconfluence.Content) (int, error) {
+	if syncCmdFlagFeatureWorkItemID > 0 {
+		return int(syncCmdFlagFeatureWorkItemID), nil
+	}
+
+	id, err := strconv.ParseUint(featureIDRegexp.FindString(content.Title), 10, 32)
+	if err != nil {
+		return 0, errors.New("unable to determine TFS feature ID from wiki page title")
+	}
+	return int(id), nil
+}
+
+// getSyncTasks parses tasks of the wiki page, prefixes their titles, adds tags and filters them according to flags.
+func getSyncTasks(content *goconfluence.Content) ([]*wiki.Task, error) {
+	tasks, err := parseTasksTable(content, syncCmdFlagVerbose)
+	if err != nil {
+		return nil, err
+	}
+	return prepareSyncTasks(tasks)
+}
+
+// prepareSyncTasks prefixes titles of parsed tasks, adds tags and filters them according to flags.
+func prepareSyncTasks(tasks []*wiki.Task) ([]*wiki.Task, error) {
+	tables, err := wiki.GroupByTable(tasks)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, table := range tables {
 		addTitlePrefixes(table, len(tables) > 1)
 		for _, t := range table.Tasks {
@@ -115,7 +325,7 @@ func syncCommand(ctx context.Context, wikiPageID int) error {
 
 	if syncCmdFlagPartNumber > 0 {
 		if int(syncCmdFlagPartNumber) > len(tables) {
-			return errors.New("invalid table (part) number")
+			return nil, errors.New("invalid table (part) number")
 		}
 		table := tables[syncCmdFlagPartNumber-1]
 		tasks = table.Tasks
@@ -132,33 +342,7 @@ func syncCommand(ctx context.Context, wikiPageID int) error {
 		}
 	})
 
-	if len(tasks) == 0 {
-		fmt.Println("nothing to create or update")
-		return nil
-	}
-
-	// remove empty and not selected tables by grouping remained tasks again
-	tables, _ = wiki.GroupByTable(tasks)
-
-	uiTables := lo.Map(tables, func(tbl *wiki.Table, _ int) tasksui.Table {
-		return tbl
-	})
-
-	ok, err := tasksui.PreviewTasks(uiTables)
-	if err != nil {
-		return err
-	}
-
-	if ok {
-		err = createTasks(ctx, int(syncCmdFlagFeatureWorkItemID), tasks)
-		if err != nil {
-			return err
-		}
-
-		err = updateWikiPage(api, content, tasks)
-	}
-
-	return err
+	return tasks, nil
 }
 
 func filterTasks(tasks []*wiki.Task, predicate func(*wiki.Task) bool) []*wiki.Task {
@@ -171,21 +355,32 @@ func filterTasks(tasks []*wiki.Task, predicate func(*wiki.Task) bool) []*wiki.Ta
 	return filtered
 }
 
-func updateWikiPage(api *wiki.API, content *goconfluence.Content, tasks []*wiki.Task) error {
+// updateWikiPage inserts TFS macros of created tasks into the wiki page, returns updated page or nil if it is not changed.
+func updateWikiPage(api *wiki.API, content *goconfluence.Content, tasks []*wiki.Task) (*goconfluence.Content, error) {
 	spinner, _ := pterm.DefaultSpinner.WithText("Updating wiki page...").Start()
 
-	body := content.Body.Storage.Value
-	updatedBody, modified, err := wiki.UpdatePageContent(body, tasks)
-	if err != nil {
-		return err
+	updated, err := saveWikiPage(api, content, tasks)
+	switch {
+	case err != nil:
+		spinner.Fail("Wiki page not updated: " + err.Error())
+	case updated == nil:
+		spinner.Success("Wiki page not changed")
+	default:
+		spinner.Success("Wiki page updated")
 	}
+	_ = spinner.Stop()
+	return updated, err
+}
 
-	if !modified {
-		spinner.Success("Wiki page not changed")
-		return nil
+// saveWikiPage saves the wiki page with inserted TFS macros of created tasks, returns nil if page is not changed.
+func saveWikiPage(api *wiki.API, content *goconfluence.Content, tasks []*wiki.Task) (*goconfluence.Content, error) {
+	body := content.Body.Storage.Value
+	updatedBody, modified, err := wiki.UpdatePageContent(body, tasks)
+	if err != nil || !modified {
+		return nil, err
 	}
 
-	_, err = api.UpdateContent(&goconfluence.Content{
+	return api.UpdateContent(&goconfluence.Content{
 		ID:    content.ID,
 		Type:  content.Type,
 		Title: content.Title,
@@ -199,20 +394,19 @@ func updateWikiPage(api *wiki.API, content *goconfluence.Content, tasks []*wiki.
 			},
 		},
 		Version: &goconfluence.Version{
-			Number: content.Version.Number + 1,
+			Number:  content.Version.Number + 1,
+			Message: syncVersionMessage,
 		},
 	})
+}
 
-	if err == nil {
-		spinner.Success("Wiki page updated")
-	} else {
-		spinner.Fail("Wiki page not updated: " + err.Error())
-	}
-	_ = spinner.Stop()
-	return err
+// createSyncComment returns comment linking task back to wiki page it is planned on.
+func createSyncComment(content *goconfluence.Content) string {
+	return fmt.Sprintf(`Synchronized with wiki page <a href="%s">%s</a>`, wiki.GetPageURL(content), html.EscapeString(content.Title))
 }
 
-func createTasks(ctx context.Context, featureID int, tasks []*wiki.Task) error {
+// createTasks creates new and updates existing tasks, comment is posted to each of them if not empty.
+func createTasks(ctx context.Context, featureID int, tasks []*wiki.Task, descriptions *descriptionConverter, comment string) error {
 	progressbar, err := pterm.DefaultProgressbar.WithTitle("Processing...").WithTotal(len(tasks)).WithRemoveWhenDone().Start()
 	if err != nil {
 		return err
@@ -229,30 +423,22 @@ func createTasks(ctx context.Context, featureID int, tasks []*wiki.Task) error {
 	}
 
 	for _, t := range tasks {
-		title := t.Title
-		if syncCmdFlagAppendTagsToTitle {
-			for _, tag := range t.Tags {
-				title = fmt.Sprintf("[%s] %s", tag, title)
-			}
-		}
+		title := getSyncTaskTitle(t)
 
 		progressbar.UpdateTitle(fmt.Sprintf("Creating %s", cutString(title, 20, true)))
 
-		if t.TfsTaskID > 0 {
-			err := a.WiClient.Update(ctx, t.TfsTaskID, title, t.Description, t.Estimate)
-			if err == nil {
-				pterm.Success.Println(fmt.Sprintf("UPDATED %s", title))
-			} else {
-				pterm.Warning.Println(fmt.Sprintf("NOT UPDATED %s: %s", title, err.Error()))
-			}
-		} else {
-			tfsTask, err := a.CreateChildTask(ctx, title, t.Description, t.Estimate, feature, t.Tags)
-			if err == nil {
-				pterm.Success.Println(fmt.Sprintf("CREATED %s", title))
-				t.Update(createTfsTaskMacro(tfsTask))
-			} else {
-				pterm.Error.Println(fmt.Sprintf("NOT CREATED %s: %s", title, err.Error()))
-			}
+		created, err := syncTask(ctx, a, feature, t, descriptions, comment)
+		switch {
+		case err == nil && created:
+			pterm.Success.Println(fmt.Sprintf("CREATED %s", title))
+		case err == nil:
+			pterm.Success.Println(fmt.Sprintf("UPDATED %s", title))
+		case created:
+			pterm.Error.Println(fmt.Sprintf("CREATED %s WITHOUT WIKI MACRO: %s", title, err.Error()))
+		case t.TfsTaskID > 0:
+			pterm.Warning.Println(fmt.Sprintf("NOT UPDATED %s: %s", title, err.Error()))
+		default:
+			pterm.Error.Println(fmt.Sprintf("NOT CREATED %s: %s", title, err.Error()))
 		}
 
 		progressbar.Increment()
@@ -262,51 +448,90 @@ func createTasks(ctx context.Context, featureID int, tasks []*wiki.Task) error {
 	return nil
 }
 
-type syncCmdTfsTaskMacroTemplateData struct {
-	Task *workitemtracking.WorkItem
-}
+// syncTask updates existing task or creates the new one and inserts its macro into the wiki task, reports whether the task is created.
+// The error is returned along with created flag if the macro of the created task is not inserted.
+// Description is converted from storage format to TFS HTML.
+func syncTask(ctx context.Context, a *tfs.API, feature *workitemtracking.WorkItem, t *wiki.Task, descriptions *descriptionConverter, comment string) (bool, error) {
+	title := getSyncTaskTitle(t)
+
+	if t.TfsTaskID > 0 {
+		// images uploaded by the previous sync are reused instead of uploading them again
+		w, err := a.WiClient.Get(ctx, t.TfsTaskID)
+		if err != nil {
+			return false, err
+		}
+		descriptions.addTfsImages(workitem.GetDescription(w))
+	}
+
+	description := descriptions.convert(ctx, a, t.Description)
+
+	if t.TfsTaskID > 0 {
+		err := a.WiClient.Update(ctx, t.TfsTaskID, title, description, t.Estimate)
+		if err != nil {
+			return false, err
+		}
+		postSyncComment(ctx, a, t.TfsTaskID, comment)
+		return false, nil
+	}
+
+	tfsTask, err := a.CreateChildTask(ctx, title, description, t.Estimate, feature, t.Tags)
+	if err != nil {
+		return false, err
+	}
+	postSyncComment(ctx, a, *tfsTask.Id, comment)
+	t.TfsTaskID = *tfsTask.Id
 
-func (t syncCmdTfsTaskMacroTemplateData) NewUUID() string {
-	return uuid.NewString()
+	// the task is created anyway, but without the macro the next sync creates it again
+	err = t.Update(renderWikiMacro(macros.Task, macros.WorkItemData(tfsTask)))
+	if err != nil {
+		return true, fmt.Errorf("macro of created task %d not inserted into wiki page: %w", *tfsTask.Id, err)
+	}
+	return true, nil
 }
 
-func createTfsTaskMacro(task *workitemtracking.WorkItem) string {
-	tfsTaskMacroPath := viper.GetString("syncCmdTfsTaskMacroPath")
+// parseTasksTable parses tasks of the wiki page from tables located by configured marker ('wikiTasksTableMarker'),
+// with verbose each table of the page is reported.
+func parseTasksTable(content *goconfluence.Content, verbose bool) ([]*wiki.Task, error) {
+	marker := wiki.DefaultTableMarker
+	err := viper.UnmarshalKey("wikiTasksTableMarker", &marker)
+	if err != nil {
+		return nil, err
+	}
 
-	if strings.TrimSpace(tfsTaskMacroPath) != "" {
-		t, ok := syncCmdTemplatesCache[tfsTaskMacroPath]
-		if !ok {
-			var err error
-			t, err = template.New("sync cmd tfs task macro template").ParseFiles(tfsTaskMacroPath)
-			if err != nil {
-				log.Fatalf("%v\n", err)
+	opts := []wiki.ParseOption{wiki.TasksTableMarker(marker), getWikiParseMacros()}
+	if verbose {
+		opts = append(opts, wiki.ReportTableDetection(func(d wiki.TableDetection) {
+			if d.Detected {
+				pterm.Info.Println(fmt.Sprintf("%s: table %d is tasks table, %s", content.Title, d.Index+1, d.Reason))
+			} else {
+				pterm.Info.Println(fmt.Sprintf("%s: table %d skipped, %s", content.Title, d.Index+1, d.Reason))
 			}
-			syncCmdTemplatesCache[tfsTaskMacroPath] = t
-		}
+		}))
+	}
 
-		var result bytes.Buffer
-		err := t.ExecuteTemplate(&result, t.Templates()[0].Name(), syncCmdTfsTaskMacroTemplateData{
-			Task: task,
-		})
-		if err != nil {
-			log.Fatalf("%v\n", err)
+	return wiki.ParseTasksTable(content.Body.Storage.Value, opts...)
+}
+
+// getSyncTaskTitle returns title of TFS task created from the wiki task.
+func getSyncTaskTitle(t *wiki.Task) string {
+	title := t.Title
+	if syncCmdFlagAppendTagsToTitle {
+		for _, tag := range t.Tags {
+			title = fmt.Sprintf("[%s] %s", tag, title)
 		}
+	}
+	return title
+}
+
+func postSyncComment(ctx context.Context, a *tfs.API, workItemID int, comment string) {
+	if comment == "" {
+		return
+	}
 
-		return result.String()
-	}
-
-	return `<div class="content-wrapper">
-			<p>
-				<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1" ac:macro-id="` + uuid.NewString() + `">
-					<ac:parameter ac:name="itemID">` + strconv.Itoa(*task.Id) + `</ac:parameter>
-					<ac:parameter ac:name="host">1</ac:parameter>
-					<ac:parameter ac:name="assigned">true</ac:parameter>
-					<ac:parameter ac:name="title">false</ac:parameter>
-					<ac:parameter ac:name="type">false</ac:parameter>
-					<ac:parameter ac:name="status">true</ac:parameter>
-				</ac:structured-macro>
-			</p>
-		</div>`
+	_, err := a.WiClient.PostComment(ctx, workItemID, comment)
+	if err != nil {
+		pterm.Warning.Println(fmt.Sprintf("COMMENT NOT POSTED %d: %s", workItemID, err.Error()))
+	}
 }
 
 func addTitlePrefixes(table *wiki.Table, withPartNumber bool) {
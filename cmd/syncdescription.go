@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// descriptionConverter converts storage format descriptions of the wiki page into TFS HTML,
+// resolved user names and uploaded images are cached, so each image is uploaded once.
+// Images uploaded by previous syncs are reused if descriptions of updated tasks are added by addTfsImages.
+type descriptionConverter struct {
+	api     *wiki.API
+	content *goconfluence.Content
+	users   *wikiUsers
+
+	mu     sync.Mutex
+	images map[string]string
+	// tfsImages are images of current TFS descriptions by file names
+	tfsImages map[string][]wiki.TfsImage
+}
+
+func newDescriptionConverter(api *wiki.API, content *goconfluence.Content) *descriptionConverter {
+	return &descriptionConverter{
+		api:       api,
+		content:   content,
+		users:     newWikiUsers(api),
+		images:    make(map[string]string),
+		tfsImages: make(map[string][]wiki.TfsImage),
+	}
+}
+
+// addTfsImages registers images of the current TFS description, so the same images are not uploaded again.
+func (c *descriptionConverter) addTfsImages(description string) {
+	images, err := wiki.GetTfsImages(description)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, image := range images {
+		c.tfsImages[image.FileName] = append(c.tfsImages[image.FileName], image)
+	}
+}
+
+// convert returns TFS HTML of the description, images are uploaded to TFS if a is not nil.
+// The description is returned as is if it can not be converted.
+func (c *descriptionConverter) convert(ctx context.Context, a *tfs.API, description string) string {
+	opts := []wiki.TfsHTMLOption{
+		wiki.ResolveUsers(c.users.resolve),
+	}
+	if templates, err := getWikiMacros(); err == nil {
+		opts = append(opts, wiki.TfsHTMLMacros(templates))
+	}
+	if a != nil {
+		opts = append(opts,
+			wiki.UploadImages(func(image wiki.StorageImage) (string, error) {
+				return c.uploadImage(ctx, a, image)
+			}),
+			wiki.WorkItemURLs(a.GetWorkItemWebURL),
+		)
+	}
+
+	converted, err := wiki.StorageToTfsHTML(description, c.content, opts...)
+	if err != nil {
+		pterm.Warning.Println(fmt.Sprintf("DESCRIPTION NOT CONVERTED %s: %s", c.content.Title, err.Error()))
+		return description
+	}
+	return converted
+}
+
+// uploadImage downloads the image attached to the wiki page and uploads it to TFS, returns URL of TFS attachment.
+// The image of TFS description with the same name and content is returned instead of uploading.
+func (c *descriptionConverter) uploadImage(ctx context.Context, a *tfs.API, image wiki.StorageImage) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pageID := image.PageID
+	if pageID == "" {
+		page, err := c.api.GetPageByTitle(image.PageTitle, image.SpaceKey)
+		if err != nil {
+			return "", err
+		}
+		pageID = page.ID
+	}
+
+	key := pageID + "/" + image.FileName
+	if url, ok := c.images[key]; ok {
+		return url, nil
+	}
+
+	var buf bytes.Buffer
+	err := c.api.DownloadAttachment(pageID, image.FileName, &buf)
+	if err != nil {
+		return "", err
+	}
+
+	if url, ok := c.findTfsImage(ctx, a, image.FileName, buf.Bytes()); ok {
+		c.images[key] = url
+		return url, nil
+	}
+
+	ref, err := a.WiClient.UploadAttachmentContent(ctx, image.FileName, &buf)
+	if err != nil {
+		return "", err
+	}
+
+	c.images[key] = *ref.Url
+	return *ref.Url, nil
+}
+
+// findTfsImage returns URL of the registered TFS image having the file name and content.
+func (c *descriptionConverter) findTfsImage(ctx context.Context, a *tfs.API, fileName string, content []byte) (string, bool) {
+	for _, image := range c.tfsImages[fileName] {
+		id, err := getTfsAttachmentID(image)
+		if err != nil {
+			continue
+		}
+
+		var buf bytes.Buffer
+		err = a.WiClient.DownloadAttachment(ctx, workitem.Attachment{ID: id, Name: image.FileName}, &buf)
+		if err == nil && bytes.Equal(buf.Bytes(), content) {
+			return image.URL, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tasker/tasksui"
+	"tasker/tfs"
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	goconfluence "github.com/virtomize/confluence-go-api"
+	"golang.org/x/sync/errgroup"
+)
+
+// syncPage is a wiki page synced along with other pages.
+type syncPage struct {
+	content   *goconfluence.Content
+	featureID int
+	tables    []*wiki.Table
+	// result of the sync
+	created int
+	updated int
+	failed  int
+	err     error
+	saved   bool
+}
+
+// syncPageTable is a tasks table of the page shown in the common preview.
+type syncPageTable struct {
+	*wiki.Table
+	name string
+}
+
+func (t syncPageTable) GetName() string { return t.name }
+
+func (p *syncPage) tasks() []*wiki.Task {
+	return lo.FlatMap(p.tables, func(t *wiki.Table, _ int) []*wiki.Task { return t.Tasks })
+}
+
+func syncPagesCommand(ctx context.Context) error {
+	if syncCmdFlagWatch {
+		return errors.New("watch mode supports single page only")
+	}
+	if syncCmdFlagFeatureWorkItemID > 0 {
+		return errors.New("feature ID is determined from title of each page, --feature can not be used with several pages")
+	}
+	if syncCmdFlagDiff {
+		return errors.New("changes are shown for single page only, --diff can not be used with several pages")
+	}
+	if syncCmdFlagConcurrency <= 0 {
+		return fmt.Errorf("invalid concurrency %d", syncCmdFlagConcurrency)
+	}
+
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pageIDs, err := findSyncPageIDs(api)
+	if err != nil {
+		return err
+	}
+
+	if len(pageIDs) == 0 {
+		fmt.Println("no pages found")
+		return nil
+	}
+
+	pages, err := loadSyncPages(api, pageIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(pages) == 0 {
+		fmt.Println("nothing to create or update")
+		return nil
+	}
+
+	var uiTables []tasksui.Table
+	for _, page := range pages {
+		for _, table := range page.tables {
+			name := page.content.Title
+			if len(page.tables) > 1 {
+				name = fmt.Sprintf("%s (part %d)", name, table.Number)
+			}
+			uiTables = append(uiTables, syncPageTable{Table: table, name: name})
+		}
+	}
+
+	ok, err := tasksui.PreviewTasks(uiTables)
+	if err != nil || !ok {
+		return err
+	}
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start(fmt.Sprintf("Syncing %d pages...", len(pages)))
+
+	wg, ctx := errgroup.WithContext(ctx)
+	wg.SetLimit(syncCmdFlagConcurrency)
+	for _, page := range pages {
+		wg.Go(func() error {
+			syncPageTasks(ctx, a, api, page)
+			spinner.UpdateText(fmt.Sprintf("Synced %s", page.content.Title))
+			return nil
+		})
+	}
+	_ = wg.Wait()
+	_ = spinner.Stop()
+
+	return printSyncPagesSummary(pages)
+}
+
+// findSyncPageIDs searches pages selected by --label, --space and --parent.
+func findSyncPageIDs(api *wiki.API) ([]string, error) {
+	filters := []string{"type=page"}
+
+	if syncCmdFlagSpace != "" {
+		filters = append(filters, fmt.Sprintf(`space="%s"`, syncCmdFlagSpace))
+	}
+
+	if syncCmdFlagParent != "" {
+		parentID := syncCmdFlagParent
+		if _, err := strconv.Atoi(parentID); err != nil {
+			if syncCmdFlagSpace == "" {
+				return nil, errors.New("space key required when parent page title used")
+			}
+
+			p, err := api.GetPageByTitle(syncCmdFlagParent, syncCmdFlagSpace)
+			if err != nil {
+				return nil, err
+			}
+			parentID = p.ID
+		}
+		filters = append(filters, "parent="+parentID)
+	}
+
+	for _, label := range syncCmdFlagLabels {
+		filters = append(filters, fmt.Sprintf(`label="%s"`, label))
+	}
+
+	results, err := api.SearchAllContent(strings.Join(filters, " AND "))
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(results, func(r goconfluence.Results, _ int) string { return r.ID }), nil
+}
+
+// loadSyncPages loads pages and their tasks, pages without feature ID in title or without tasks are skipped.
+func loadSyncPages(api *wiki.API, pageIDs []string) ([]*syncPage, error) {
+	progressbar, err := pterm.DefaultProgressbar.WithTitle("Loading pages...").WithTotal(len(pageIDs)).WithRemoveWhenDone().Start()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = progressbar.Stop()
+	}()
+
+	var pages []*syncPage
+	for _, pageID := range pageIDs {
+		content, err := getPageWithBody(api, pageID)
+		if err != nil {
+			return nil, err
+		}
+		progressbar.Increment()
+
+		featureID, err := getSyncFeatureID(content)
+		if err != nil {
+			pterm.Warning.Println(fmt.Sprintf("SKIPPED %s: %s", content.Title, err.Error()))
+			continue
+		}
+
+		tasks, err := getSyncTasks(content)
+		if err != nil {
+			pterm.Warning.Println(fmt.Sprintf("SKIPPED %s: %s", content.Title, err.Error()))
+			continue
+		}
+
+		if len(tasks) == 0 {
+			continue
+		}
+
+		tables, _ := wiki.GroupByTable(tasks)
+		pages = append(pages, &syncPage{
+			content:   content,
+			featureID: featureID,
+			tables:    tables,
+		})
+	}
+
+	return pages, nil
+}
+
+// syncPageTasks creates and updates tasks of the page and inserts macros of created tasks, the result is stored in the page.
+func syncPageTasks(ctx context.Context, a *tfs.API, api *wiki.API, page *syncPage) {
+	feature, err := a.WiClient.Get(ctx, page.featureID)
+	if err != nil {
+		page.err = err
+		return
+	}
+
+	var comment string
+	if syncCmdFlagComment {
+		comment = createSyncComment(page.content)
+	}
+
+	descriptions := newDescriptionConverter(api, page.content)
+	tasks := page.tasks()
+	for _, t := range tasks {
+		created, err := syncTask(ctx, a, feature, t, descriptions, comment)
+		switch {
+		case err != nil:
+			page.failed++
+			page.err = errors.Join(page.err, err)
+		case created:
+			page.created++
+		default:
+			page.updated++
+		}
+	}
+
+	updated, err := saveWikiPage(api, page.content, tasks)
+	if err != nil {
+		page.err = errors.Join(page.err, err)
+	}
+	page.saved = updated != nil
+}
+
+func printSyncPagesSummary(pages []*syncPage) error {
+	tableData := [][]string{{"Page", "Feature", "Created", "Updated", "Failed", "Wiki page", "Error"}}
+	var failed int
+	for _, page := range pages {
+		wikiPage := "not changed"
+		if page.saved {
+			wikiPage = "updated"
+		}
+
+		errText := ""
+		if page.err != nil {
+			failed++
+			errText = pterm.FgRed.Sprint(page.err.Error())
+		}
+
+		tableData = append(tableData, []string{
+			cutString(page.content.Title, 50, false),
+			strconv.Itoa(page.featureID),
+			strconv.Itoa(page.created),
+			strconv.Itoa(page.updated),
+			strconv.Itoa(page.failed),
+			wikiPage,
+			errText,
+		})
+	}
+
+	err := pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pages are not synced completely", failed, len(pages))
+	}
+	return nil
+}
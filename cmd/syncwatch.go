@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tasker/prettyprint"
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+	"tasker/wiki/macros"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// syncWatchState is the state of the watched wiki page after the last sync cycle.
+type syncWatchState struct {
+	version int
+	// revisions are revisions of the page tasks in TFS.
+	revisions map[int]int
+	// pending is set when some tasks were not created because of the creation limit or the page was not saved.
+	pending bool
+	// created are tasks created by previous cycles until their macros are saved to the page,
+	// so the rows are not created again when the page is edited concurrently.
+	created wiki.CreatedTasks
+}
+
+func watchSyncCommand(ctx context.Context, wikiPageID int) error {
+	if syncCmdFlagDiff {
+		return errors.New("watch mode syncs without preview, --diff can not be used with --watch")
+	}
+
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	if syncCmdFlagWatchMaxCreate <= 0 {
+		return fmt.Errorf("invalid max count of created tasks %d", syncCmdFlagWatchMaxCreate)
+	}
+
+	pterm.Info.Println(fmt.Sprintf("Watching wiki page %d every %s, press Ctrl+C to stop", wikiPageID, syncCmdFlagWatchInterval))
+
+	state := &syncWatchState{created: make(wiki.CreatedTasks)}
+	for {
+		changed, err := state.changed(ctx, a, api, wikiPageID)
+		if err == nil && changed {
+			err = syncWatchCycle(ctx, a, api, wikiPageID, state)
+		}
+		if err != nil {
+			// the page may be temporarily unavailable or edited concurrently, so try again next time,
+			// macros of created tasks are inserted into the latest version of the page instead of creating them again
+			pterm.Error.Println(fmt.Sprintf("%s SYNC FAILED: %s", time.Now().Format("15:04:05"), err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(syncCmdFlagWatchInterval):
+		}
+	}
+}
+
+// changed checks whether the page version or revisions of its tasks are changed since the last cycle.
+func (s *syncWatchState) changed(ctx context.Context, a *tfs.API, api *wiki.API, wikiPageID int) (bool, error) {
+	if s.pending {
+		return true, nil
+	}
+
+	page, err := api.GetContentByID(strconv.Itoa(wikiPageID), goconfluence.ContentQuery{
+		Expand: []string{"version"},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if page.Version.Number != s.version {
+		return true, nil
+	}
+
+	if !syncCmdFlagWatchWorkItems || len(s.revisions) == 0 {
+		return false, nil
+	}
+
+	revisions, err := getWorkItemRevisions(ctx, a, lo.Keys(s.revisions))
+	if err != nil {
+		return false, err
+	}
+
+	for id, rev := range revisions {
+		if s.revisions[id] != rev {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// syncWatchCycle creates new tasks and updates changed ones without preview, creations are limited by --max-create.
+func syncWatchCycle(ctx context.Context, a *tfs.API, api *wiki.API, wikiPageID int, state *syncWatchState) error {
+	content, err := getPageWithBody(api, strconv.Itoa(wikiPageID))
+	if err != nil {
+		return err
+	}
+
+	featureID, err := getSyncFeatureID(content)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := getSyncTasks(content)
+	if err != nil {
+		return err
+	}
+
+	// rows of tasks created by previous cycles get their IDs, their macros are inserted below
+	restored := state.created.Apply(tasks)
+
+	existingIDs := lo.FilterMap(tasks, func(t *wiki.Task, _ int) (int, bool) {
+		return t.TfsTaskID, t.TfsTaskID > 0
+	})
+
+	workItems := make(map[int]*workitemtracking.WorkItem)
+	if len(existingIDs) > 0 {
+		list, err := a.WiClient.GetList(ctx, lo.Uniq(existingIDs))
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			workItems[*list[i].Id] = &list[i]
+		}
+	}
+
+	for _, t := range restored {
+		w, ok := workItems[t.TfsTaskID]
+		if !ok {
+			return fmt.Errorf("created task %d not found", t.TfsTaskID)
+		}
+		err = t.Update(renderWikiMacro(macros.Task, macros.WorkItemData(w)))
+		if err != nil {
+			return fmt.Errorf("macro of created task %d not inserted into wiki page: %w", t.TfsTaskID, err)
+		}
+	}
+
+	descriptions := newDescriptionConverter(api, content)
+
+	var newTasks, changedTasks []*wiki.Task
+	for _, t := range tasks {
+		if t.TfsTaskID == 0 {
+			newTasks = append(newTasks, t)
+		} else if w, ok := workItems[t.TfsTaskID]; ok && isSyncTaskChanged(ctx, t, w, descriptions) {
+			changedTasks = append(changedTasks, t)
+		}
+	}
+
+	state.pending = false
+	if len(newTasks) > syncCmdFlagWatchMaxCreate {
+		pterm.Warning.Println(fmt.Sprintf("%d new tasks exceed the limit, %d of them will be created next cycle", len(newTasks), len(newTasks)-syncCmdFlagWatchMaxCreate))
+		newTasks = newTasks[:syncCmdFlagWatchMaxCreate]
+		state.pending = true
+	}
+
+	state.version = content.Version.Number
+
+	if len(newTasks) > 0 || len(changedTasks) > 0 {
+		pterm.Info.Println(fmt.Sprintf("%s page version %d: %d to create, %d to update", time.Now().Format("15:04:05"), content.Version.Number, len(newTasks), len(changedTasks)))
+
+		var comment string
+		if syncCmdFlagComment {
+			comment = createSyncComment(content)
+		}
+
+		err = createTasks(ctx, featureID, append(newTasks, changedTasks...), descriptions, comment)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range newTasks {
+			if t.TfsTaskID > 0 {
+				state.created.Add(t, t.TfsTaskID)
+			}
+		}
+	}
+
+	if len(newTasks) > 0 || len(restored) > 0 {
+		updated, err := updateWikiPage(api, content, tasks)
+		if err != nil {
+			// created tasks are remembered, so the next cycle inserts their macros into the latest version of the page
+			state.pending = true
+			return err
+		}
+		if updated != nil && updated.Version != nil {
+			state.version = updated.Version.Number
+		}
+	}
+
+	if syncCmdFlagWatchWorkItems {
+		ids := lo.FilterMap(tasks, func(t *wiki.Task, _ int) (int, bool) {
+			return t.TfsTaskID, t.TfsTaskID > 0
+		})
+		state.revisions, err = getWorkItemRevisions(ctx, a, lo.Uniq(ids))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isSyncTaskChanged compares fields updated by sync, descriptions are compared as text, since TFS reformats HTML.
+// Images are not uploaded for comparison, they do not affect the text.
+func isSyncTaskChanged(ctx context.Context, t *wiki.Task, w *workitemtracking.WorkItem, descriptions *descriptionConverter) bool {
+	return getSyncTaskTitle(t) != workitem.GetTitle(w) ||
+		prettyprint.HTMLText(descriptions.convert(ctx, nil, t.Description)) != prettyprint.HTMLText(workitem.GetDescription(w))
+}
+
+func getWorkItemRevisions(ctx context.Context, a *tfs.API, ids []int) (map[int]int, error) {
+	revisions := make(map[int]int, len(ids))
+	if len(ids) == 0 {
+		return revisions, nil
+	}
+
+	list, err := a.WiClient.GetList(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range list {
+		if w.Rev != nil {
+			revisions[*w.Id] = *w.Rev
+		}
+	}
+
+	return revisions, nil
+}
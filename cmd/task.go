@@ -17,9 +17,14 @@ var (
 	createTaskCmd = &cobra.Command{
 		Use:   "create <Title> [Description]",
 		Short: "Create new task",
-		Long:  `Create new task in current sprint`,
-		Args:  cobra.RangeArgs(1, 2),
+		Long: `Create new task in current sprint.
+Description is written in Markdown and converted to HTML. It can be passed as argument, read from file or stdin ("-"),
+if description is not specified, the editor ($VISUAL or $EDITOR) is opened.
+Named work item templates from 'tfsWorkItemTemplates' config key can be used with --template flag.`,
+		Args: cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
+			cobra.CheckErr(applyWorkItemTemplateDefaults(cmd))
+
 			title := ""
 			if len(args) > 0 {
 				title = args[0]
@@ -30,7 +35,10 @@ var (
 				description = args[1]
 			}
 
-			err := createTaskCommand(cmd.Context(), title, description)
+			description, err := getDescription(description, createTaskCmdFlagDescriptionFile, title, createTaskCmdFlagHTMLDescription, !createTaskCmdFlagNoEdit)
+			cobra.CheckErr(err)
+
+			err = createTaskCommand(cmd.Context(), title, description)
 
 			cobra.CheckErr(err)
 		},
@@ -43,6 +51,11 @@ var (
 	createTaskCmdFlagUnassignedTask    bool
 	createTaskCmdFlagWorkitemType      string
 	createTaskCmdFlagCurrentIteration  bool
+	createTaskCmdFlagTemplate          string
+	createTaskCmdFlagDescriptionFile   string
+	createTaskCmdFlagHTMLDescription   bool
+	createTaskCmdFlagNoEdit            bool
+	createTaskCmdFlagAttachments       []string
 )
 
 func init() {
@@ -60,8 +73,11 @@ func init() {
 	createTaskCmd.PersistentFlags().BoolVarP(&createTaskCmdFlagUnassignedTask, "unassigned", "u", false, "Do not assign task")
 	createTaskCmd.PersistentFlags().StringVarP(&createTaskCmdFlagWorkitemType, "type", "", "Task", "Type of workitem (Task, Requirement, etc.)")
 	createTaskCmd.PersistentFlags().BoolVarP(&createTaskCmdFlagCurrentIteration, "current", "c", false, "Assign current iteration path")
-
-	cobra.CheckErr(createTaskCmd.MarkPersistentFlagRequired("estimate"))
+	createTaskCmd.PersistentFlags().StringVarP(&createTaskCmdFlagTemplate, "template", "", "", "Name of work item template from config")
+	createTaskCmd.PersistentFlags().StringVarP(&createTaskCmdFlagDescriptionFile, "description-file", "F", "", "Read description from file (\"-\" for stdin)")
+	createTaskCmd.PersistentFlags().BoolVarP(&createTaskCmdFlagHTMLDescription, "html", "", false, "Description is HTML, do not convert it from Markdown")
+	createTaskCmd.PersistentFlags().BoolVarP(&createTaskCmdFlagNoEdit, "no-edit", "", false, "Do not open editor if description is not specified")
+	createTaskCmd.PersistentFlags().StringSliceVarP(&createTaskCmdFlagAttachments, "attach", "", nil, "Files to attach to the task. Can be separated by comma or specified multiple times.")
 
 	cobra.CheckErr(viper.BindPFlag("tfsProject", createTaskCmd.PersistentFlags().Lookup("project")))
 	cobra.CheckErr(viper.BindPFlag("tfsTeam", createTaskCmd.PersistentFlags().Lookup("team")))
@@ -82,6 +98,21 @@ func createTaskCommand(ctx context.Context, title, description string) error {
 		return err
 	}
 
+	if createTaskCmdFlagTemplate != "" {
+		task, children, err := createWorkItemByTemplate(ctx, api, createTaskCmdFlagTemplate, workItemTemplateData{
+			Title:       title,
+			Description: description,
+		}, parentUserStoryNamePattern, nil, createTaskCmdFlagCurrentIteration)
+		if err == nil {
+			err = attachFiles(ctx, api, task, createTaskCmdFlagAttachments)
+		}
+		printCreateTaskResult(task, err, spinner)
+		printCreatedChildren(children)
+		openInBrowser(task)
+
+		return err
+	}
+
 	task, err := api.CreateWorkItem(
 		ctx,
 		createTaskCmdFlagWorkitemType,
@@ -95,6 +126,9 @@ func createTaskCommand(ctx context.Context, title, description string) error {
 		!createTaskCmdFlagUnassignedTask,
 		createTaskCmdFlagCurrentIteration,
 	)
+	if err == nil {
+		err = attachFiles(ctx, api, task, createTaskCmdFlagAttachments)
+	}
 	printCreateTaskResult(task, err, spinner)
 	openInBrowser(task)
 
@@ -117,6 +151,12 @@ func printCreateTaskResult(task *workitemtracking.WorkItem, err error, spinner *
 	}
 }
 
+func printCreatedChildren(children []*workitemtracking.WorkItem) {
+	for _, child := range children {
+		pterm.Success.Println(workitem.GetURL(child))
+	}
+}
+
 func openInBrowser(task *workitemtracking.WorkItem) {
 	if createTaskCmdFlagOpenTaskInBrowser && task != nil {
 		href := workitem.GetURL(task)
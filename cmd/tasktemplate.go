@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"tasker/markdown"
+	"tasker/ptr"
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const defaultWorkItemTemplateTitle = "{{.Title}}"
+
+type workItemTemplateField struct {
+	Field string `mapstructure:"field"`
+	Value any    `mapstructure:"value"`
+}
+
+type workItemTemplateChild struct {
+	Title             string                  `mapstructure:"title"`
+	Description       string                  `mapstructure:"description"`
+	DescriptionFormat string                  `mapstructure:"descriptionFormat"`
+	Estimate          float32                 `mapstructure:"estimate"`
+	Tags              []string                `mapstructure:"tags"`
+	Discipline        string                  `mapstructure:"discipline"`
+	Fields            []workItemTemplateField `mapstructure:"fields"`
+}
+
+type workItemTemplate struct {
+	workItemTemplateChild `mapstructure:",squash"`
+	Type                  string                  `mapstructure:"type"`
+	Parent                string                  `mapstructure:"parent"`
+	Children              []workItemTemplateChild `mapstructure:"children"`
+}
+
+type workItemTemplateWorkItem struct {
+	ID     int
+	Title  string
+	URL    string
+	Fields map[string]any
+}
+
+type workItemTemplateData struct {
+	Title       string
+	Description string
+	// Parent is the parent work item of created one.
+	Parent *workItemTemplateWorkItem
+	// WorkItem is the created work item, available in children templates only.
+	WorkItem *workItemTemplateWorkItem
+	// Bug is the bug for which the task is created, available in bugfix command only.
+	Bug *workItemTemplateWorkItem
+}
+
+func newWorkItemTemplateWorkItem(w *workitemtracking.WorkItem) *workItemTemplateWorkItem {
+	if w == nil {
+		return nil
+	}
+
+	result := &workItemTemplateWorkItem{
+		ID:    *w.Id,
+		Title: workitem.GetTitle(w),
+		URL:   workitem.GetURL(w),
+	}
+	if w.Fields != nil {
+		result.Fields = *w.Fields
+	}
+	return result
+}
+
+func getWorkItemTemplate(name string) (*workItemTemplate, error) {
+	var templates map[string]*workItemTemplate
+	err := viper.UnmarshalKey("tfsWorkItemTemplates", &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, ok := templates[strings.ToLower(name)]
+	if !ok || tpl == nil {
+		return nil, fmt.Errorf("work item template '%s' not found", name)
+	}
+
+	return tpl, nil
+}
+
+// applyWorkItemTemplateDefaults sets flags not specified by user to values of selected template
+// and checks the estimate is specified one way or another.
+func applyWorkItemTemplateDefaults(cmd *cobra.Command) error {
+	if createTaskCmdFlagTemplate != "" {
+		tpl, err := getWorkItemTemplate(createTaskCmdFlagTemplate)
+		if err != nil {
+			return err
+		}
+
+		if tpl.Type != "" && !cmd.Flags().Changed("type") {
+			createTaskCmdFlagWorkitemType = tpl.Type
+		}
+
+		if tpl.Estimate > 0 && !cmd.Flags().Changed("estimate") {
+			createTaskCmdFlagEstimate = tpl.Estimate
+		}
+	}
+
+	if createTaskCmdFlagEstimate <= 0 && !cmd.Flags().Changed("estimate") {
+		return errors.New(`required flag(s) "estimate" not set`)
+	}
+
+	return nil
+}
+
+func createWorkItemByTemplate(ctx context.Context, a *tfs.API, name string, data workItemTemplateData, parentNamePattern string, relations []*workitem.Relation, currentIter bool) (*workitemtracking.WorkItem, []*workitemtracking.WorkItem, error) {
+	tpl, err := getWorkItemTemplate(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parentID := int(createTaskCmdFlagParentWorkItemID)
+	if parentID == 0 && tpl.Parent != "" {
+		parentNamePattern, err = renderWorkItemTemplateString("parent", tpl.Parent, data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if id, err := strconv.Atoi(parentNamePattern); err == nil {
+			parentID = id
+		}
+	}
+
+	parent, err := a.FindParent(ctx, parentID, parentNamePattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	data.Parent = newWorkItemTemplateWorkItem(parent)
+
+	title, description, fields, err := renderWorkItemTemplate(&tpl.workItemTemplateChild, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags := slices.Concat(tpl.Tags, createTaskCmdFlagTags)
+
+	task, err := a.CreateWorkItem(ctx, createTaskCmdFlagWorkitemType, title, description, createTaskCmdFlagEstimate, *parent.Id, relations, tags, "", !createTaskCmdFlagUnassignedTask, currentIter, fields...)
+	if err != nil {
+		return task, nil, err
+	}
+	data.WorkItem = newWorkItemTemplateWorkItem(task)
+
+	var children []*workitemtracking.WorkItem
+	for i := range tpl.Children {
+		child := &tpl.Children[i]
+		title, description, fields, err := renderWorkItemTemplate(child, data)
+		if err != nil {
+			return task, children, err
+		}
+
+		childTask, err := a.CreateChildTask(ctx, title, description, child.Estimate, task, slices.Concat(child.Tags, createTaskCmdFlagTags), fields...)
+		if err != nil {
+			return task, children, err
+		}
+		children = append(children, childTask)
+	}
+
+	return task, children, nil
+}
+
+func renderWorkItemTemplate(tpl *workItemTemplateChild, data workItemTemplateData) (string, string, []*workitem.Field, error) {
+	titleTemplate := tpl.Title
+	if titleTemplate == "" {
+		titleTemplate = defaultWorkItemTemplateTitle
+	}
+
+	title, err := renderWorkItemTemplateString("title", titleTemplate, data)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	description := data.Description
+	if tpl.Description != "" {
+		description, err = renderWorkItemTemplateString("description", tpl.Description, data)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	if strings.EqualFold(tpl.DescriptionFormat, "markdown") || strings.EqualFold(tpl.DescriptionFormat, "md") {
+		description, err = markdown.ToHTML(description)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	var fields []*workitem.Field
+	if tpl.Discipline != "" {
+		fields = append(fields, &workitem.Field{
+			Path:  ptr.FromStr("/fields/Microsoft.VSTS.Common.Discipline"),
+			Value: tpl.Discipline,
+		})
+	}
+
+	for _, field := range tpl.Fields {
+		value := field.Value
+		if str, ok := value.(string); ok {
+			value, err = renderWorkItemTemplateString(field.Field, str, data)
+			if err != nil {
+				return "", "", nil, err
+			}
+		}
+
+		fields = append(fields, &workitem.Field{
+			Path:  ptr.FromStr("/fields/" + field.Field),
+			Value: value,
+		})
+	}
+
+	return title, description, fields, nil
+}
+
+func renderWorkItemTemplateString(name, templateString string, data workItemTemplateData) (string, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(templateString)
+	if err != nil {
+		return "", err
+	}
+
+	var result bytes.Buffer
+	err = t.Execute(&result, data)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(result.String()), nil
+}
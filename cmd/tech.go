@@ -11,8 +11,8 @@ import (
 	"tasker/tasksui"
 	"tasker/tfs"
 	"tasker/wiki"
+	"tasker/wiki/macros"
 
-	"github.com/eiannone/keyboard"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
 	"github.com/pterm/pterm"
 	"github.com/samber/lo"
@@ -222,12 +222,14 @@ func createTechDebtTasks(ctx context.Context, pages []*techDebtPage, tfsAPI *tfs
 		tags := []string{}
 		tags = append(tags, page.Labels...)
 
+		description := newDescriptionConverter(wikiAPI, page.content).convert(ctx, tfsAPI, page.Description)
+
 		var tfsTask *workitemtracking.WorkItem
 		switch syncTechCmdFlagTfsWorkItemType {
 		case "Task":
-			tfsTask, err = tfsAPI.CreateChildTask(ctx, page.Title, page.Description, page.estimate, requirement, tags)
+			tfsTask, err = tfsAPI.CreateChildTask(ctx, page.Title, description, page.estimate, requirement, tags)
 		case "Requirement":
-			tfsTask, err = tfsAPI.CreateChildRequirement(ctx, "Technical", page.Title, page.Description, page.estimate, page.priority, requirement, tags)
+			tfsTask, err = tfsAPI.CreateChildRequirement(ctx, "Technical", page.Title, description, page.estimate, page.priority, requirement, tags)
 		default:
 			return fmt.Errorf("unknown work item type: %s", syncTechCmdFlagTfsWorkItemType)
 		}
@@ -236,7 +238,7 @@ func createTechDebtTasks(ctx context.Context, pages []*techDebtPage, tfsAPI *tfs
 			pterm.Error.Println(fmt.Sprintf("TFS Task NOT CREATED %s: %s", page.Title, err.Error()))
 		} else {
 			progressbar.UpdateTitle(fmt.Sprintf("Updating wiki page %s", cutString(page.Title, 20, true)))
-			page.AddTfsTask(*tfsTask.Id)
+			page.AddTfsTask(*tfsTask.Id, renderWikiMacro(macros.Requirement, macros.WorkItemData(tfsTask)))
 			err = updateTechDebtWikiPage(wikiAPI, page)
 
 			if err != nil {
@@ -291,7 +293,7 @@ func parseTechDebtPages(ctx context.Context, pageIDs []string, api *wiki.API) ([
 				estimation = float64(syncTechCmdFlagTfsEstimate)
 			}
 
-			techDebt, err := wiki.ParseTechDebt(content)
+			techDebt, err := wiki.ParseTechDebt(content, getWikiParseMacros())
 			if err != nil {
 				return err
 			}
@@ -349,25 +351,6 @@ func updateTechDebtWikiPage(api *wiki.API, page *techDebtPage) error {
 	return err
 }
 
-func requestConfirmationTechDebt() (bool, error) {
-	pterm.DefaultHeader.
-		WithFullWidth().
-		WithBackgroundStyle(pterm.NewStyle(pterm.BgDefault)).
-		WithTextStyle(pterm.NewStyle(pterm.FgCyan)).
-		Print("Press ENTER to continue. Any other key for cancel.")
-
-	_, key, err := keyboard.GetSingleKey()
-	if err != nil {
-		return false, err
-	}
-
-	if key != keyboard.KeyEnter {
-		return false, nil
-	}
-
-	return true, nil
-}
-
 func previewTechDebtTasks(pages []*techDebtPage) {
 	titleWidth, descriptionWidth := getTechDebtColumnsWidth()
 
@@ -458,7 +441,7 @@ func archiveTechCommand(ctx context.Context) error {
 
 	previewTechDebtTasks(pages)
 
-	ok, err := requestConfirmationTechDebt()
+	ok, err := requestEnterConfirmation()
 	if err != nil {
 		return err
 	}
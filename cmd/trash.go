@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"tasker/tfs"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreWorkItemsCmd = &cobra.Command{
+		Use:   "restore <Work Item ID, ...>",
+		Short: "Restore work items",
+		Long:  "Restore deleted work items from the recycle bin.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var workItemIDs []int
+
+			for i := range args {
+				workItemID, err := strconv.Atoi(args[i])
+				cobra.CheckErr(err)
+				workItemIDs = append(workItemIDs, workItemID)
+			}
+
+			err := restoreWorkItemsCommand(cmd.Context(), workItemIDs)
+			cobra.CheckErr(err)
+		},
+	}
+
+	trashCmd = &cobra.Command{
+		Use:   "trash",
+		Short: "Manage recycle bin",
+		Long:  "Manage deleted work items.",
+	}
+
+	trashListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List deleted work items",
+		Long:  "List work items in the recycle bin.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := trashListCommand(cmd.Context())
+			cobra.CheckErr(err)
+		},
+	}
+
+	trashListCmdFlagLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(restoreWorkItemsCmd)
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+
+	trashListCmd.Flags().IntVarP(&trashListCmdFlagLimit, "limit", "", 50, "Max count of shown work items, the most recently deleted are shown")
+}
+
+func restoreWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, workItemID := range workItemIDs {
+		err = a.WiClient.Restore(ctx, workItemID)
+		if err != nil {
+			failed++
+			pterm.Error.Println(fmt.Sprintf("NOT RESTORED %d: %s", workItemID, err.Error()))
+		} else {
+			pterm.Success.Println(fmt.Sprintf("RESTORED %d", workItemID))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d work items not restored", failed)
+	}
+	return nil
+}
+
+func trashListCommand(ctx context.Context) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := a.WiClient.GetDeleted(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		fmt.Println("recycle bin is empty")
+		return nil
+	}
+
+	if trashListCmdFlagLimit > 0 && len(deleted) > trashListCmdFlagLimit {
+		deleted = deleted[:trashListCmdFlagLimit]
+	}
+
+	tableData := [][]string{{"ID", "Type", "Title", "Deleted by", "Deleted at"}}
+	for _, d := range deleted {
+		tableData = append(tableData, []string{
+			strconv.Itoa(d.ID),
+			d.Type,
+			d.Title,
+			d.DeletedBy,
+			d.DeletedAt.Local().Format("02.01.2006 15:04"),
+		})
+	}
+
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
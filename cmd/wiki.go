@@ -57,8 +57,9 @@ If page titles used, space key required.`,
 	deleteWikiCmd = &cobra.Command{
 		Use:   "delete <Page ID|Title, ...>",
 		Short: "Delete wiki pages",
-		Long:  `Delete wiki pages.`,
-		Args:  cobra.ArbitraryArgs,
+		Long: `Delete wiki pages.
+Pages are moved to the trash and can be restored by 'tasker wiki restore'.`,
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			deleteWikiCmdFlagDeletingPages = append(deleteWikiCmdFlagDeletingPages, args...)
 			err := deleteWikiPagesCommand(cmd.Context())
@@ -66,6 +67,28 @@ If page titles used, space key required.`,
 		},
 	}
 
+	restoreWikiCmd = &cobra.Command{
+		Use:   "restore <Page ID, ...>",
+		Short: "Restore wiki pages",
+		Long:  `Restore deleted wiki pages from the trash.`,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := restoreWikiPagesCommand(args)
+			cobra.CheckErr(err)
+		},
+	}
+
+	trashWikiCmd = &cobra.Command{
+		Use:   "trash",
+		Short: "List deleted wiki pages",
+		Long:  `List wiki pages in the trash of the space.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := trashWikiCommand()
+			cobra.CheckErr(err)
+		},
+	}
+
 	copyWikiCmd = &cobra.Command{
 		Use:   "copy <Page ID|Title, ...>",
 		Short: "Copy wiki pages",
@@ -147,6 +170,10 @@ If page titles used, space key required.`,
 
 	deleteWikiCmdFlagDeletingPages []string
 	deleteWikiCmdFlagDeleteChild   bool
+	deleteWikiCmdFlagYes           bool
+
+	trashWikiCmdFlagSpace string
+	trashWikiCmdFlagLimit int
 
 	moveWikiCmdFlagNewParentPage string
 	moveWikiCmdFlagMovingPages   []string
@@ -187,8 +214,15 @@ func init() {
 	wikiCmd.AddCommand(workitemsFromWikiPageCmd)
 	wikiCmd.AddCommand(copyWikiCmd)
 	wikiCmd.AddCommand(deleteWikiCmd)
+	wikiCmd.AddCommand(restoreWikiCmd)
+	wikiCmd.AddCommand(trashWikiCmd)
 
 	deleteWikiCmd.Flags().BoolVarP(&deleteWikiCmdFlagDeleteChild, "del-child", "", false, "Delete child pages")
+	deleteWikiCmd.Flags().BoolVarP(&deleteWikiCmdFlagYes, "yes", "y", false, "Do not ask for confirmation")
+
+	trashWikiCmd.Flags().StringVarP(&trashWikiCmdFlagSpace, "space", "s", "", "Space key")
+	trashWikiCmd.Flags().IntVarP(&trashWikiCmdFlagLimit, "limit", "", 50, "Results limit")
+	cobra.CheckErr(trashWikiCmd.MarkFlagRequired("space"))
 
 	moveWikiCmd.Flags().StringVarP(&moveWikiCmdFlagNewParentPage, "target", "t", "", "ID or title of target parent Wiki page")
 	moveWikiCmd.Flags().StringSliceVarP(&moveWikiCmdFlagMovingPages, "page", "p", nil, "ID or title of moving page")
@@ -197,7 +231,7 @@ func init() {
 
 	uploadWikiContentCmd.Flags().UintVarP(&uploadWikiContentCmdFlagTargetID, "target", "t", 0, "ID of target Wiki page")
 	uploadWikiContentCmd.Flags().StringVarP(&uploadWikiContentCmdFlagSourcePath, "file", "f", "", "Path to file with wiki markup")
-	uploadWikiContentCmd.Flags().StringVarP(&uploadWikiContentCmdFlagContentType, "type", "", "wiki", "Content type (wiki, storage, editor, md, etc.)")
+	uploadWikiContentCmd.Flags().StringVarP(&uploadWikiContentCmdFlagContentType, "type", "", "wiki", "Content type (wiki, storage, editor, md (markdown macro), md-native, etc.)")
 	uploadWikiContentCmd.Flags().BoolVarP(&uploadWikiContentCmdFlagAddTableOfContents, "add-table-of-contents", "", false, "Prepend content with 'Table of Contents' wiki macros")
 	uploadWikiContentCmd.Flags().UintVarP(&uploadWikiContentCmdFlagHeaderLevel, "header-level", "", 2, "Max Header Level of Table of Contents wiki macros")
 	uploadWikiContentCmd.Flags().BoolVarP(&uploadWikiContentCmdFlagFixRefs, "fix-refs", "", false, "Fix relative references")
@@ -258,19 +292,44 @@ func deleteWikiPagesCommand(ctx context.Context) error {
 		return err
 	}
 
-	progressbar, err := pterm.DefaultProgressbar.WithTitle("Processing...").WithTotal(len(deleteWikiCmdFlagDeletingPages)).WithRemoveWhenDone().Start()
+	var trees []*wiki.PageNode
+	for _, pageID := range deleteWikiCmdFlagDeletingPages {
+		tree, err := api.GetPageTree(pageID)
+		if err != nil {
+			return err
+		}
+
+		if len(tree.Children) > 0 && !deleteWikiCmdFlagDeleteChild {
+			return fmt.Errorf("page %s has children", pageID)
+		}
+
+		trees = append(trees, tree)
+	}
+
+	if !deleteWikiCmdFlagYes {
+		ok, err := confirmDeleteWikiPages(trees)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errors.New("canceled by user")
+		}
+	}
+
+	progressbar, err := pterm.DefaultProgressbar.WithTitle("Processing...").WithTotal(len(trees)).WithRemoveWhenDone().Start()
 	if err != nil {
 		return err
 	}
 
-	for _, pageID := range deleteWikiCmdFlagDeletingPages {
-		progressbar.UpdateTitle(fmt.Sprintf("Deleting... %v", pageID))
+	for _, tree := range trees {
+		progressbar.UpdateTitle(fmt.Sprintf("Deleting... %v", tree.Title))
 
-		err = deleteWikiPage(api, pageID, deleteWikiCmdFlagDeleteChild)
+		err = deleteWikiPageTree(api, tree)
 		if err != nil {
-			pterm.Error.Println(fmt.Sprintf("NOT DELETED %v: %s", pageID, err.Error()))
+			pterm.Error.Println(fmt.Sprintf("NOT DELETED %v: %s", tree.ID, err.Error()))
 		} else {
-			pterm.Success.Println(fmt.Sprintf("DELETED %v", pageID))
+			pterm.Success.Println(fmt.Sprintf("DELETED %v", tree.ID))
 		}
 	}
 
@@ -279,26 +338,88 @@ func deleteWikiPagesCommand(ctx context.Context) error {
 	return err
 }
 
-func deleteWikiPage(api *wiki.API, pageID string, deleteChildPages bool) error {
-	childPages, err := api.GetChildPages(pageID)
+// confirmDeleteWikiPages shows the full subtrees of deleting pages and asks user for confirmation.
+func confirmDeleteWikiPages(trees []*wiki.PageNode) (bool, error) {
+	var items pterm.LeveledList
+	var count int
+	var addItems func(node *wiki.PageNode, depth int)
+	addItems = func(node *wiki.PageNode, depth int) {
+		items = append(items, pterm.LeveledListItem{Level: depth, Text: fmt.Sprintf("%s (%s)", node.Title, node.ID)})
+		count++
+		for _, child := range node.Children {
+			addItems(child, depth+1)
+		}
+	}
+	for _, tree := range trees {
+		addItems(tree, 0)
+	}
+
+	err := pterm.DefaultTree.WithRoot(pterm.NewTreeFromLeveledList(items)).Render()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if len(childPages.Results) > 0 && !deleteChildPages {
-		return fmt.Errorf("page %s has children", pageID)
+	pterm.Warning.Println(fmt.Sprintf("%d page(s) will be moved to the trash.", count))
+	return requestEnterConfirmation()
+}
+
+// deleteWikiPageTree deletes the page with all its descendants, children are deleted first.
+func deleteWikiPageTree(api *wiki.API, tree *wiki.PageNode) error {
+	return tree.Walk(func(node *wiki.PageNode, _ int) error {
+		_, err := api.DelContent(node.ID)
+		return err
+	})
+}
+
+func restoreWikiPagesCommand(pageIDs []string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
 	}
 
-	for _, childPage := range childPages.Results {
-		err = deleteWikiPage(api, childPage.ID, deleteChildPages)
+	var failed int
+	for _, pageID := range pageIDs {
+		err = api.RestorePage(pageID)
 		if err != nil {
-			return err
+			failed++
+			pterm.Error.Println(fmt.Sprintf("NOT RESTORED %v: %s", pageID, err.Error()))
+		} else {
+			pterm.Success.Println(fmt.Sprintf("RESTORED %v", pageID))
 		}
 	}
 
-	_, err = api.DelContent(pageID)
+	if failed > 0 {
+		return fmt.Errorf("%d pages not restored", failed)
+	}
+	return nil
+}
 
-	return err
+func trashWikiCommand() error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pages, err := api.GetTrashedPages(trashWikiCmdFlagSpace, trashWikiCmdFlagLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(pages) == 0 {
+		fmt.Println("trash is empty")
+		return nil
+	}
+
+	tableData := [][]string{{"ID", "Title", "Version"}}
+	for _, page := range pages {
+		version := ""
+		if page.Version != nil {
+			version = strconv.Itoa(page.Version.Number)
+		}
+		tableData = append(tableData, []string{page.ID, page.Title, version})
+	}
+
+	return pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
 }
 
 func copyWikiPagesCommand() error {
@@ -358,10 +479,30 @@ func uploadWikiPageContentCommand() error {
 			return fmt.Errorf("failed to fetch child pages: %w", err)
 		}
 
+		var trees []*wiki.PageNode
 		for _, cp := range childPages.Results {
-			err = deleteWikiPage(api, cp.ID, true)
+			tree, err := api.GetPageTree(cp.ID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch child pages: %w", err)
+			}
+			trees = append(trees, tree)
+		}
+
+		if len(trees) > 0 {
+			ok, err := confirmDeleteWikiPages(trees)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return errors.New("canceled by user")
+			}
+		}
+
+		for _, tree := range trees {
+			err = deleteWikiPageTree(api, tree)
 			if err != nil {
-				return fmt.Errorf("failed to delete child page %v: %w", cp, err)
+				return fmt.Errorf("failed to delete child page %v: %w", tree.ID, err)
 			}
 		}
 	}
@@ -520,7 +661,7 @@ func workitemsFromWikiPageCommand(pages []string) error {
 			return err
 		}
 
-		tasks, err := wiki.ParseTfsTasks(content)
+		tasks, err := wiki.ParseTfsTasks(content, getWikiParseMacros())
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"tasker/diff"
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+var (
+	diffWikiCmd = &cobra.Command{
+		Use:   "diff <Page ID|Title>",
+		Short: "Show changes of wiki page between versions",
+		Long: `Show changes of wiki page between versions: added, removed and changed rows of tasks tables
+(matched by task title) and changed lines of the page text converted to Markdown.
+By default the current version is compared with the previous one.
+If page title used, space key required.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := diffWikiCommand(args[0])
+			cobra.CheckErr(err)
+		},
+	}
+
+	diffWikiCmdFlagSpace   string
+	diffWikiCmdFlagFrom    int
+	diffWikiCmdFlagTo      int
+	diffWikiCmdFlagContext int
+)
+
+func init() {
+	wikiCmd.AddCommand(diffWikiCmd)
+
+	diffWikiCmd.Flags().StringVarP(&diffWikiCmdFlagSpace, "space", "s", "", "Space key of the page")
+	diffWikiCmd.Flags().IntVar(&diffWikiCmdFlagFrom, "from", 0, "Old version of the page (default previous to --to)")
+	diffWikiCmd.Flags().IntVar(&diffWikiCmdFlagTo, "to", 0, "New version of the page (default current)")
+	diffWikiCmd.Flags().IntVar(&diffWikiCmdFlagContext, "context", 2, "Count of unchanged lines shown around changed ones")
+}
+
+func diffWikiCommand(pageIDOrTitle string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	page, err := getWikiPage(api, pageIDOrTitle, diffWikiCmdFlagSpace)
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Loading page versions...")
+	oldPage, newPage, err := getWikiPageVersions(api, page.ID, diffWikiCmdFlagFrom, diffWikiCmdFlagTo)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+	_ = spinner.Stop()
+
+	pterm.DefaultSection.Printfln("%s: version %d → %d", newPage.Title, oldPage.Version.Number, newPage.Version.Number)
+
+	oldTasks, err := parseTasksTable(oldPage, false)
+	if err != nil {
+		return err
+	}
+	newTasks, err := parseTasksTable(newPage, false)
+	if err != nil {
+		return err
+	}
+	printTasksDiff(wiki.DiffTasks(oldTasks, newTasks))
+
+	var opts []wiki.MarkdownOption
+	if templates, err := getWikiMacros(); err == nil {
+		opts = append(opts, wiki.MarkdownMacros(templates))
+	}
+	oldText, err := wiki.StorageToMarkdown(oldPage.Body.Storage.Value, oldPage, opts...)
+	if err != nil {
+		return err
+	}
+	newText, err := wiki.StorageToMarkdown(newPage.Body.Storage.Value, newPage, opts...)
+	if err != nil {
+		return err
+	}
+	printPageTextDiff(diff.Lines(splitPageLines(oldText), splitPageLines(newText)), diffWikiCmdFlagContext)
+
+	return nil
+}
+
+// getWikiPageVersions returns old and new versions of the page with bodies, zero versions mean
+// the current version and the version previous to the new one.
+func getWikiPageVersions(api *wiki.API, pageID string, from, to int) (*goconfluence.Content, *goconfluence.Content, error) {
+	current, err := api.GetPageVersion(pageID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if to <= 0 || to > current.Version.Number {
+		to = current.Version.Number
+	}
+	if from <= 0 {
+		from = to - 1
+	}
+	if from < 1 || from >= to {
+		return nil, nil, fmt.Errorf("invalid versions to compare: %d and %d, the page has %d versions", from, to, current.Version.Number)
+	}
+
+	newPage := current
+	if to != current.Version.Number {
+		newPage, err = api.GetPageVersion(pageID, to)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	oldPage, err := api.GetPageVersion(pageID, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldPage, newPage, nil
+}
+
+func printTasksDiff(changes []wiki.TaskDiff) {
+	if len(changes) == 0 {
+		pterm.Info.Println("tasks not changed")
+		return
+	}
+
+	titleWidth, descriptionWidth := getColumnsWidth()
+
+	tableData := [][]string{{"", "Title", "Changes"}}
+	for _, d := range changes {
+		switch d.Kind {
+		case wiki.TaskAdded:
+			tableData = append(tableData, []string{
+				pterm.FgGreen.Sprint("+"),
+				pterm.FgGreen.Sprint(cutString(d.New.Title, titleWidth, false)),
+				pterm.FgGreen.Sprint(fmt.Sprintf("estimate %v", d.New.Estimate)),
+			})
+		case wiki.TaskRemoved:
+			tableData = append(tableData, []string{
+				pterm.FgRed.Sprint("-"),
+				pterm.FgRed.Sprint(cutString(d.Old.Title, titleWidth, false)),
+				pterm.FgRed.Sprint(fmt.Sprintf("estimate %v", d.Old.Estimate)),
+			})
+		case wiki.TaskChanged:
+			fields := make([]string, 0, len(d.Fields))
+			for _, field := range d.Fields {
+				switch field {
+				case "description":
+					fields = append(fields, "description: "+cutString(d.New.Description, descriptionWidth, false))
+				case "estimate":
+					fields = append(fields, fmt.Sprintf("estimate: %v → %v", d.Old.Estimate, d.New.Estimate))
+				case "tfs":
+					fields = append(fields, fmt.Sprintf("tfs: %d → %d", d.Old.TfsTaskID, d.New.TfsTaskID))
+				case "tags":
+					fields = append(fields, fmt.Sprintf("tags: %s → %s", d.Old.GetTagsString(), d.New.GetTagsString()))
+				}
+			}
+			tableData = append(tableData, []string{
+				pterm.FgYellow.Sprint("~"),
+				pterm.FgYellow.Sprint(cutString(d.New.Title, titleWidth, false)),
+				strings.Join(fields, "\n"),
+			})
+		}
+	}
+
+	_ = pterm.DefaultTable.
+		WithHasHeader().
+		WithRowSeparator("-").
+		WithData(tableData).
+		Render()
+}
+
+// printPageTextDiff prints changed lines with context lines around them, skipped lines are replaced by '...'.
+func printPageTextDiff(lines []diff.Line, context int) {
+	if !diff.Changed(lines) {
+		pterm.Info.Println("text not changed")
+		return
+	}
+
+	shown := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Op == diff.Equal {
+			continue
+		}
+		for j := max(0, i-context); j <= min(len(lines)-1, i+context); j++ {
+			shown[j] = true
+		}
+	}
+
+	skipped := false
+	for i, line := range lines {
+		if !shown[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			fmt.Println(pterm.FgGray.Sprint("..."))
+			skipped = false
+		}
+
+		switch line.Op {
+		case diff.Delete:
+			fmt.Println(pterm.FgRed.Sprint("- " + line.Text))
+		case diff.Insert:
+			fmt.Println(pterm.FgGreen.Sprint("+ " + line.Text))
+		default:
+			fmt.Println("  " + line.Text)
+		}
+	}
+	if skipped {
+		fmt.Println(pterm.FgGray.Sprint("..."))
+	}
+}
+
+func splitPageLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
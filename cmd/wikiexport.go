@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportWikiCmd = &cobra.Command{
+		Use:   "export <Page ID|Title>",
+		Short: "Export wiki pages to Markdown files",
+		Long: `Export wiki page (with --recursive, the whole page tree) to Markdown files.
+Tables, code macros, admonitions and tasks are converted to GFM, attachments are downloaded next to page files
+and links between exported pages and to attachments become relative file paths.
+With --recursive the page becomes index.md of the output directory, pages with children become subdirectories,
+so the directory can be published back by 'tasker wiki publish'.
+If page title used, space key required.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := exportWikiCommand(args[0])
+			cobra.CheckErr(err)
+		},
+	}
+
+	exportWikiCmdFlagSpace     string
+	exportWikiCmdFlagRecursive bool
+	exportWikiCmdFlagOut       string
+)
+
+func init() {
+	wikiCmd.AddCommand(exportWikiCmd)
+
+	exportWikiCmd.Flags().StringVarP(&exportWikiCmdFlagSpace, "space", "s", "", "Space key of the page")
+	exportWikiCmd.Flags().BoolVarP(&exportWikiCmdFlagRecursive, "recursive", "r", false, "Export child pages")
+	exportWikiCmd.Flags().StringVarP(&exportWikiCmdFlagOut, "out", "o", "", "Output directory")
+	cobra.CheckErr(exportWikiCmd.MarkFlagRequired("out"))
+	cobra.CheckErr(exportWikiCmd.MarkFlagDirname("out"))
+}
+
+// exportedWikiPage is the page with the path of its Markdown file relative to the output directory.
+type exportedWikiPage struct {
+	node *wiki.PageNode
+	file string
+	// isIndex is set for the root page exported with children, its title is not written as the heading.
+	isIndex bool
+}
+
+// assets returns the directory of downloaded attachments of the page.
+func (p *exportedWikiPage) assets() string {
+	return strings.TrimSuffix(p.file, ".md") + ".assets"
+}
+
+func exportWikiCommand(pageIDOrTitle string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	root, err := getWikiPage(api, pageIDOrTitle, exportWikiCmdFlagSpace)
+	if err != nil {
+		return err
+	}
+
+	tree := &wiki.PageNode{ID: root.ID, Title: root.Title}
+	if exportWikiCmdFlagRecursive {
+		spinner, _ := pterm.DefaultSpinner.Start("Loading wiki pages...")
+		tree, err = api.GetPageTree(root.ID)
+		if err != nil {
+			spinner.Fail(err.Error())
+			return err
+		}
+		_ = spinner.Stop()
+	}
+
+	pages := getExportedWikiPages(tree)
+	byTitle := make(map[string]*exportedWikiPage, len(pages))
+	for _, p := range pages {
+		byTitle[p.node.Title] = p
+	}
+
+	users := newWikiUsers(api)
+
+	for _, p := range pages {
+		err = exportWikiPage(api, p, root.Space.Key, byTitle, users)
+		if err != nil {
+			pterm.Error.Println(fmt.Sprintf("NOT EXPORTED %s (%s): %s", p.node.Title, p.node.ID, err.Error()))
+			return err
+		}
+		pterm.Success.Println(fmt.Sprintf("EXPORTED %s (%s) to %s", p.node.Title, p.node.ID, p.file))
+	}
+
+	return nil
+}
+
+// getExportedWikiPages assigns files to the pages: the root page with children becomes index.md,
+// other pages with children become index.md of subdirectories named by titles.
+func getExportedWikiPages(tree *wiki.PageNode) []*exportedWikiPage {
+	used := make(map[string]bool)
+	fileName := func(dir string, node *wiki.PageNode, suffix string) string {
+		name := path.Join(dir, getExportFileName(node.Title)+suffix)
+		if used[name] {
+			name = path.Join(dir, getExportFileName(node.Title+" "+node.ID)+suffix)
+		}
+		used[name] = true
+		return name
+	}
+
+	if len(tree.Children) == 0 {
+		return []*exportedWikiPage{{node: tree, file: fileName("", tree, ".md")}}
+	}
+
+	pages := []*exportedWikiPage{{node: tree, file: "index.md", isIndex: true}}
+	used["index.md"] = true
+
+	var assign func(node *wiki.PageNode, dir string)
+	assign = func(node *wiki.PageNode, dir string) {
+		for _, child := range node.Children {
+			if len(child.Children) == 0 {
+				pages = append(pages, &exportedWikiPage{node: child, file: fileName(dir, child, ".md")})
+				continue
+			}
+
+			childDir := fileName(dir, child, "")
+			pages = append(pages, &exportedWikiPage{node: child, file: path.Join(childDir, "index.md")})
+			assign(child, childDir)
+		}
+	}
+	assign(tree, "")
+
+	return pages
+}
+
+// getExportFileName replaces characters not allowed in file names.
+func getExportFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, title)
+
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+func exportWikiPage(api *wiki.API, p *exportedWikiPage, spaceKey string, byTitle map[string]*exportedWikiPage, users *wikiUsers) error {
+	content, err := getPageWithBody(api, p.node.ID)
+	if err != nil {
+		return err
+	}
+
+	err = downloadWikiPageAttachments(api, p)
+	if err != nil {
+		return err
+	}
+
+	opts := []wiki.MarkdownOption{
+		wiki.PageFiles(func(pageSpaceKey, title string) (string, bool) {
+			target, ok := byTitle[title]
+			if !ok || (pageSpaceKey != "" && pageSpaceKey != spaceKey) {
+				return "", false
+			}
+			return getRelativeExportPath(p.file, target.file), true
+		}),
+		wiki.AttachmentFiles(func(image wiki.StorageImage) (string, bool) {
+			target := p
+			if image.PageID != p.node.ID {
+				var ok bool
+				target, ok = byTitle[image.PageTitle]
+				if !ok || image.SpaceKey != spaceKey {
+					return "", false
+				}
+			}
+			return getRelativeExportPath(p.file, path.Join(target.assets(), getExportFileName(image.FileName))), true
+		}),
+		wiki.MarkdownUsers(users.resolve),
+	}
+	if templates, err := getWikiMacros(); err == nil {
+		opts = append(opts, wiki.MarkdownMacros(templates))
+	}
+
+	markdown, err := wiki.StorageToMarkdown(content.Body.Storage.Value, content, opts...)
+	if err != nil {
+		return err
+	}
+
+	if !p.isIndex {
+		markdown = "# " + content.Title + "\n\n" + markdown
+	}
+
+	file := filepath.Join(exportWikiCmdFlagOut, filepath.FromSlash(p.file))
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(markdown), 0o644)
+}
+
+// downloadWikiPageAttachments downloads all attachments of the page into its assets directory.
+func downloadWikiPageAttachments(api *wiki.API, p *exportedWikiPage) error {
+	ids, err := api.GetAttachmentIDs(p.node.ID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	dir := filepath.Join(exportWikiCmdFlagOut, filepath.FromSlash(p.assets()))
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for name := range ids {
+		f, err := os.Create(filepath.Join(dir, getExportFileName(name)))
+		if err != nil {
+			return err
+		}
+
+		err = api.DownloadAttachment(p.node.ID, name, f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// getRelativeExportPath returns slash separated path of the file relative to the directory of the page file.
+func getRelativeExportPath(pageFile, file string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(pageFile)), filepath.FromSlash(file))
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+
+	"github.com/google/uuid"
+	"github.com/pterm/pterm"
+)
+
+// wikiImages collects TFS images of descriptions converted into storage format,
+// they are attached to the wiki page once it is created.
+type wikiImages struct {
+	// images are keyed by attachment file name
+	images map[string]wiki.TfsImage
+}
+
+func newWikiImages() *wikiImages {
+	return &wikiImages{images: make(map[string]wiki.TfsImage)}
+}
+
+// convert returns storage format of TFS HTML description, TFS images are referenced as page attachments.
+func (w *wikiImages) convert(description string) string {
+	opts := []wiki.StorageOption{wiki.AttachImages(w.add)}
+	if templates, err := getWikiMacros(); err == nil {
+		opts = append(opts, wiki.MentionMacros(templates))
+	}
+
+	storage, err := wiki.TfsHTMLToStorage(description, opts...)
+	if err != nil {
+		return wiki.HTMLToStorage(description)
+	}
+	return storage
+}
+
+// add registers the image and returns its attachment file name, prefixed with attachment ID since TFS names
+// all pasted images 'image.png'.
+func (w *wikiImages) add(image wiki.TfsImage) (string, error) {
+	id, err := getTfsAttachmentID(image)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := strings.Split(id.String(), "-")[0] + "-" + image.FileName
+	w.images[fileName] = image
+	return fileName, nil
+}
+
+// upload downloads collected images from TFS and attaches them to the wiki page.
+func (w *wikiImages) upload(ctx context.Context, a *tfs.API, api *wiki.API, pageID string) error {
+	for fileName, image := range w.images {
+		id, err := getTfsAttachmentID(image)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		err = a.WiClient.DownloadAttachment(ctx, workitem.Attachment{ID: id, Name: image.FileName}, &buf)
+		if err != nil {
+			return err
+		}
+
+		_, err = api.UploadAttachment(pageID, fileName, &buf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadOrWarn uploads images reporting failure as warning, since the page is already created.
+func (w *wikiImages) uploadOrWarn(ctx context.Context, a *tfs.API, api *wiki.API, pageID string) {
+	if len(w.images) == 0 {
+		return
+	}
+
+	err := w.upload(ctx, a, api, pageID)
+	if err != nil {
+		pterm.Warning.Println(fmt.Sprintf("IMAGES NOT ATTACHED: %s", err.Error()))
+	}
+}
+
+func getTfsAttachmentID(image wiki.TfsImage) (uuid.UUID, error) {
+	u := image.URL
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	return uuid.Parse(path.Base(u))
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+var (
+	labelWikiCmd = &cobra.Command{
+		Use:   "label",
+		Short: "Manage labels of wiki pages",
+		Long: `Add, remove or list labels of wiki pages.
+Pages are specified by IDs or titles (space key required when titles used) or selected by --cql query,
+with --recursive labels of all descendants of the pages are changed too.`,
+	}
+
+	labelWikiAddCmd = &cobra.Command{
+		Use:   "add <Page ID|Title, ...>",
+		Short: "Add labels to wiki pages",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := labelWikiAddCommand(args)
+			cobra.CheckErr(err)
+		},
+	}
+
+	labelWikiRemoveCmd = &cobra.Command{
+		Use:   "remove <Page ID|Title, ...>",
+		Short: "Remove labels from wiki pages",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := labelWikiRemoveCommand(args)
+			cobra.CheckErr(err)
+		},
+	}
+
+	labelWikiListCmd = &cobra.Command{
+		Use:   "list <Page ID|Title, ...>",
+		Short: "List labels of wiki pages",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := labelWikiListCommand(args)
+			cobra.CheckErr(err)
+		},
+	}
+
+	labelWikiCmdFlagLabels    []string
+	labelWikiCmdFlagSpace     string
+	labelWikiCmdFlagCQL       string
+	labelWikiCmdFlagRecursive bool
+)
+
+func init() {
+	wikiCmd.AddCommand(labelWikiCmd)
+	labelWikiCmd.AddCommand(labelWikiAddCmd)
+	labelWikiCmd.AddCommand(labelWikiRemoveCmd)
+	labelWikiCmd.AddCommand(labelWikiListCmd)
+
+	labelWikiCmd.PersistentFlags().StringVarP(&labelWikiCmdFlagSpace, "space", "s", "", "Space key of the pages")
+	labelWikiCmd.PersistentFlags().StringVar(&labelWikiCmdFlagCQL, "cql", "", "CQL query selecting the pages, ie 'ancestor=123 AND title~\"API\"'")
+	labelWikiCmd.PersistentFlags().BoolVarP(&labelWikiCmdFlagRecursive, "recursive", "r", false, "Apply to child pages too")
+
+	for _, cmd := range []*cobra.Command{labelWikiAddCmd, labelWikiRemoveCmd} {
+		cmd.Flags().StringSliceVarP(&labelWikiCmdFlagLabels, "label", "l", nil, "Labels. Can be separated by comma or specified multiple times.")
+		cobra.CheckErr(cmd.MarkFlagRequired("label"))
+	}
+}
+
+func labelWikiAddCommand(pagesIDOrTitle []string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pages, err := getLabeledWikiPages(api, pagesIDOrTitle)
+	if err != nil {
+		return err
+	}
+
+	labels := lo.Map(getWikiLabelNames(), func(name string, _ int) goconfluence.Label {
+		return goconfluence.Label{Prefix: "global", Name: name}
+	})
+
+	var failed int
+	for _, page := range pages {
+		_, err = api.AddLabels(page.ID, &labels)
+		if err != nil {
+			failed++
+			pterm.Error.Println(fmt.Sprintf("NOT LABELED %s (%s): %s", page.Title, page.ID, err.Error()))
+			continue
+		}
+		pterm.Success.Println(fmt.Sprintf("LABELED %s (%s)", page.Title, page.ID))
+	}
+
+	return getLabelWikiError(failed)
+}
+
+func labelWikiRemoveCommand(pagesIDOrTitle []string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pages, err := getLabeledWikiPages(api, pagesIDOrTitle)
+	if err != nil {
+		return err
+	}
+
+	names := getWikiLabelNames()
+
+	var failed int
+	for _, page := range pages {
+		// missing label can't be deleted, so only labels of the page are removed
+		labels, err := api.GetLabels(page.ID)
+		if err != nil {
+			failed++
+			pterm.Error.Println(fmt.Sprintf("NOT UNLABELED %s (%s): %s", page.Title, page.ID, err.Error()))
+			continue
+		}
+
+		removed := lo.Filter(labels.Labels, func(label goconfluence.Label, _ int) bool {
+			return lo.Contains(names, label.Name)
+		})
+		if len(removed) == 0 {
+			pterm.Info.Println(fmt.Sprintf("NOT LABELED %s (%s)", page.Title, page.ID))
+			continue
+		}
+
+		for _, label := range removed {
+			_, err = api.DeleteLabel(page.ID, label.Name)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			failed++
+			pterm.Error.Println(fmt.Sprintf("NOT UNLABELED %s (%s): %s", page.Title, page.ID, err.Error()))
+			continue
+		}
+		pterm.Success.Println(fmt.Sprintf("UNLABELED %s (%s)", page.Title, page.ID))
+	}
+
+	return getLabelWikiError(failed)
+}
+
+func labelWikiListCommand(pagesIDOrTitle []string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	pages, err := getLabeledWikiPages(api, pagesIDOrTitle)
+	if err != nil {
+		return err
+	}
+
+	tableData := [][]string{{"ID", "Title", "Labels"}}
+	for _, page := range pages {
+		labels, err := api.GetLabels(page.ID)
+		if err != nil {
+			return err
+		}
+
+		tableData = append(tableData, []string{
+			page.ID,
+			page.Title,
+			strings.Join(lo.Map(labels.Labels, func(label goconfluence.Label, _ int) string { return label.Name }), ", "),
+		})
+	}
+
+	return pterm.DefaultTable.
+		WithHasHeader().
+		WithData(tableData).
+		Render()
+}
+
+// getLabeledWikiPages returns pages specified by arguments and found by --cql query,
+// with --recursive their descendants are returned too. Each page is returned once.
+func getLabeledWikiPages(api *wiki.API, pagesIDOrTitle []string) ([]*wiki.PageNode, error) {
+	if len(pagesIDOrTitle) == 0 && labelWikiCmdFlagCQL == "" {
+		return nil, errors.New("pages or --cql query required")
+	}
+
+	var roots []*wiki.PageNode
+	for _, pageIDOrTitle := range pagesIDOrTitle {
+		page, err := getWikiPage(api, pageIDOrTitle, labelWikiCmdFlagSpace)
+		if err != nil {
+			return nil, fmt.Errorf("page %s: %w", pageIDOrTitle, err)
+		}
+		roots = append(roots, &wiki.PageNode{ID: page.ID, Title: page.Title})
+	}
+
+	if labelWikiCmdFlagCQL != "" {
+		found, err := searchWikiPages(api, labelWikiCmdFlagCQL)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, found...)
+	}
+
+	var pages []*wiki.PageNode
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		if labelWikiCmdFlagRecursive {
+			tree, err := api.GetPageTree(root.ID)
+			if err != nil {
+				return nil, err
+			}
+			root = tree
+		}
+
+		// parents first, like pages are shown in the tree
+		var visit func(node *wiki.PageNode)
+		visit = func(node *wiki.PageNode) {
+			if !seen[node.ID] {
+				seen[node.ID] = true
+				pages = append(pages, node)
+			}
+			for _, child := range node.Children {
+				visit(child)
+			}
+		}
+		visit(root)
+	}
+
+	return pages, nil
+}
+
+// searchWikiPages returns all pages found by CQL query, the query is limited to pages unless it sets the type.
+func searchWikiPages(api *wiki.API, cql string) ([]*wiki.PageNode, error) {
+	if !strings.Contains(cql, "type=") && !strings.Contains(cql, "type =") {
+		cql = "type=page AND (" + cql + ")"
+	}
+
+	results, err := api.SearchAllContent(cql)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(results, func(r goconfluence.Results, _ int) *wiki.PageNode {
+		return &wiki.PageNode{ID: r.ID, Title: r.Title}
+	}), nil
+}
+
+// getWikiLabelNames returns names of --label flag, Confluence stores labels in lower case.
+func getWikiLabelNames() []string {
+	return lo.Uniq(lo.FilterMap(labelWikiCmdFlagLabels, func(label string, _ int) (string, bool) {
+		label = strings.ToLower(strings.TrimSpace(label))
+		return label, label != ""
+	}))
+}
+
+func getLabelWikiError(failed int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d pages failed", failed)
+	}
+	return nil
+}
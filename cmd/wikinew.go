@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+	"tasker/wiki/macros"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+const (
+	defaultWikiPageTemplateName  = "planning"
+	defaultWikiPageTemplateTitle = "{{.Feature.ID}} {{.Feature.Title}}"
+	defaultWikiPageTemplateBody  = `<ac:structured-macro ac:name="toc" ac:schema-version="1" />
+<h2>Фича</h2>
+{{.FeatureMacro}}
+{{.Feature.Description}}
+<h2>Требования</h2>
+<p><br/></p>
+<h2>Решение</h2>
+<p><br/></p>
+{{.TasksTable}}`
+)
+
+var (
+	newWikiPageCmd = &cobra.Command{
+		Use:   "new",
+		Short: "Create wiki page by template",
+		Long: `Create wiki page of the feature by template from config ('wikiPageTemplates').
+The page body is Go template of storage format filled with feature title and description from TFS.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := newWikiPageCommand(cmd.Context())
+			cobra.CheckErr(err)
+		},
+	}
+
+	newWikiPageCmdFlagTemplate     string
+	newWikiPageCmdFlagFeatureID    int
+	newWikiPageCmdFlagParentPageID uint
+)
+
+type wikiPageTemplate struct {
+	// Path is a path to file with Go template of page body in storage format.
+	Path string `mapstructure:"path"`
+	// Title is Go template of page title.
+	Title string `mapstructure:"title"`
+	// Parent is ID of the page new pages are created under.
+	Parent uint `mapstructure:"parent"`
+	// Columns are the columns of the empty tasks table.
+	Columns []string `mapstructure:"columns"`
+}
+
+type wikiPageTemplateFeature struct {
+	ID          int
+	Title       string
+	URL         string
+	Description string
+	Fields      map[string]any
+}
+
+type wikiPageTemplateData struct {
+	Feature *wikiPageTemplateFeature
+	// FeatureMacro is TFS macro of the feature.
+	FeatureMacro string
+	// TasksTable is the empty tasks table with the heading 'sync' command expects.
+	TasksTable string
+}
+
+func init() {
+	wikiCmd.AddCommand(newWikiPageCmd)
+
+	newWikiPageCmd.Flags().StringVarP(&newWikiPageCmdFlagTemplate, "template", "t", defaultWikiPageTemplateName, "Name of page template")
+	newWikiPageCmd.Flags().IntVarP(&newWikiPageCmdFlagFeatureID, "feature", "f", 0, "ID of TFS feature work item")
+	newWikiPageCmd.Flags().UintVarP(&newWikiPageCmdFlagParentPageID, "parent-page", "p", 0, "ID of parent wiki page (overrides template parent)")
+	cobra.CheckErr(newWikiPageCmd.MarkFlagRequired("feature"))
+}
+
+// getWikiPageTemplate returns configured page template, the 'planning' template is available without configuration.
+func getWikiPageTemplate(name string) (*wikiPageTemplate, error) {
+	var templates map[string]*wikiPageTemplate
+	err := viper.UnmarshalKey("wikiPageTemplates", &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, ok := templates[strings.ToLower(name)]
+	if !ok || tpl == nil {
+		if name != defaultWikiPageTemplateName {
+			return nil, fmt.Errorf("wiki page template '%s' not found", name)
+		}
+		tpl = &wikiPageTemplate{}
+	}
+
+	if tpl.Title == "" {
+		tpl.Title = defaultWikiPageTemplateTitle
+	}
+
+	return tpl, nil
+}
+
+func newWikiPageCommand(ctx context.Context) error {
+	tpl, err := getWikiPageTemplate(newWikiPageCmdFlagTemplate)
+	if err != nil {
+		return err
+	}
+
+	parentPageID := tpl.Parent
+	if newWikiPageCmdFlagParentPageID > 0 {
+		parentPageID = newWikiPageCmdFlagParentPageID
+	}
+	if parentPageID == 0 {
+		return errors.New("parent page is not configured in template, specify it by --parent-page")
+	}
+
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	feature, err := a.WiClient.Get(ctx, newWikiPageCmdFlagFeatureID)
+	if err != nil {
+		return err
+	}
+
+	parent, err := api.GetPageByID(strconv.Itoa(int(parentPageID)))
+	if err != nil {
+		return err
+	}
+
+	images := newWikiImages()
+	data := wikiPageTemplateData{
+		Feature: &wikiPageTemplateFeature{
+			ID:          *feature.Id,
+			Title:       workitem.GetTitle(feature),
+			URL:         a.GetWorkItemWebURL(*feature.Id),
+			Description: images.convert(workitem.GetDescription(feature)),
+			Fields:      *feature.Fields,
+		},
+		FeatureMacro: renderWikiMacro(macros.Feature, macros.WorkItemData(feature)),
+		TasksTable:   wiki.RenderEmptyTasksTable(tpl.Columns),
+	}
+
+	title, err := renderWikiPageTemplate("title", tpl.Title, data)
+	if err != nil {
+		return err
+	}
+
+	bodyTemplate := defaultWikiPageTemplateBody
+	if tpl.Path != "" {
+		content, err := os.ReadFile(tpl.Path)
+		if err != nil {
+			return err
+		}
+		bodyTemplate = string(content)
+	}
+
+	body, err := renderWikiPageTemplate("body", bodyTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	page, err := api.CreateContent(&goconfluence.Content{
+		Type:  "page",
+		Title: strings.TrimSpace(title),
+		Ancestors: []goconfluence.Ancestor{
+			{ID: parent.ID},
+		},
+		Space: &goconfluence.Space{
+			Key: parent.Space.Key,
+		},
+		Body: goconfluence.Body{
+			Storage: goconfluence.Storage{
+				Value:          body,
+				Representation: "storage",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	images.uploadOrWarn(ctx, a, api, page.ID)
+
+	pterm.Success.Println(fmt.Sprintf("CREATED %s", page.Title))
+	fmt.Println(wiki.GetPageURL(page))
+	return nil
+}
+
+// renderWikiPageTemplate renders storage format template, 'escape' function escapes plain text values like titles.
+func renderWikiPageTemplate(name, text string, data wikiPageTemplateData) (string, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{
+		"escape": html.EscapeString,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("wiki page template %s: %w", name, err)
+	}
+
+	var result bytes.Buffer
+	err = t.Execute(&result, data)
+	if err != nil {
+		return "", fmt.Errorf("wiki page template %s: %w", name, err)
+	}
+
+	return result.String(), nil
+}
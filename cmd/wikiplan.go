@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tasker/tfs"
+	"tasker/tfs/workitem"
+	"tasker/wiki"
+	"tasker/wiki/macros"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+var (
+	planWikiCmd = &cobra.Command{
+		Use:   "plan <Feature ID>",
+		Short: "Create wiki page of feature",
+		Long: `Create wiki page titled with feature ID and title with tasks table filled from child tasks of the feature.
+The page can be maintained by 'tasker sync' then.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			featureID, err := strconv.Atoi(args[0])
+			cobra.CheckErr(err)
+
+			err = planWikiCommand(cmd.Context(), featureID)
+			cobra.CheckErr(err)
+		},
+	}
+
+	// devTitlePrefixRegexp matches the prefix 'sync' prepends to task titles, so it is not doubled on the next sync.
+	devTitlePrefixRegexp = regexp.MustCompile(`^\[DEV\]\s*`)
+
+	planWikiCmdFlagParentPageID uint
+)
+
+func init() {
+	wikiCmd.AddCommand(planWikiCmd)
+
+	planWikiCmd.Flags().UintVarP(&planWikiCmdFlagParentPageID, "parent-page", "p", 0, "ID of parent wiki page")
+	cobra.CheckErr(planWikiCmd.MarkFlagRequired("parent-page"))
+}
+
+func planWikiCommand(ctx context.Context, featureID int) error {
+	a, err := tfs.NewAPI(ctx)
+	if err != nil {
+		return err
+	}
+
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Loading feature...")
+	tree, err := a.WiClient.GetTree(ctx, featureID)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+	_ = spinner.Stop()
+
+	parent, err := api.GetPageByID(strconv.Itoa(int(planWikiCmdFlagParentPageID)))
+	if err != nil {
+		return err
+	}
+
+	pageIDs, err := findFeaturePageIDs(api, featureID, parent.Space.Key)
+	if err != nil {
+		return err
+	}
+	if len(pageIDs) > 0 {
+		return fmt.Errorf("wiki page of feature %d already exists (%s), use 'tasker sync' to update it", featureID, strings.Join(pageIDs, ", "))
+	}
+
+	tasks := getPlanTasks(tree)
+	images := newWikiImages()
+	body := renderFeaturePlanStorage(a, tree.WorkItem, tasks, images)
+
+	page, err := api.CreateContent(&goconfluence.Content{
+		Type:  "page",
+		Title: fmt.Sprintf("%d %s", featureID, workitem.GetTitle(tree.WorkItem)),
+		Ancestors: []goconfluence.Ancestor{
+			{ID: parent.ID},
+		},
+		Space: &goconfluence.Space{
+			Key: parent.Space.Key,
+		},
+		Body: goconfluence.Body{
+			Storage: goconfluence.Storage{
+				Value:          body,
+				Representation: "storage",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	images.uploadOrWarn(ctx, a, api, page.ID)
+
+	pterm.Success.Println(fmt.Sprintf("CREATED %s (%d tasks)", page.Title, len(tasks)))
+	fmt.Println(wiki.GetPageURL(page))
+	return nil
+}
+
+// getPlanTasks returns child tasks of the feature ordered by ID, i.e. in the order they were created.
+func getPlanTasks(tree *workitem.TreeNode) []*workitemtracking.WorkItem {
+	var tasks []*workitemtracking.WorkItem
+	for _, child := range tree.Children {
+		if workitem.GetType(child.WorkItem) == "Task" {
+			tasks = append(tasks, child.WorkItem)
+		}
+	}
+
+	slices.SortFunc(tasks, func(t1, t2 *workitemtracking.WorkItem) int {
+		return *t1.Id - *t2.Id
+	})
+
+	return tasks
+}
+
+func renderFeaturePlanStorage(a *tfs.API, feature *workitemtracking.WorkItem, tasks []*workitemtracking.WorkItem, images *wikiImages) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(`<p>Фича: <a href="%s">%d %s</a></p>`,
+		html.EscapeString(a.GetWorkItemWebURL(*feature.Id)),
+		*feature.Id,
+		html.EscapeString(workitem.GetTitle(feature)),
+	))
+
+	workItems := make(map[int]*workitemtracking.WorkItem, len(tasks))
+	var pageTasks []*wiki.Task
+	for _, task := range tasks {
+		workItems[*task.Id] = task
+		pageTasks = append(pageTasks, &wiki.Task{
+			Title:       devTitlePrefixRegexp.ReplaceAllString(workitem.GetTitle(task), ""),
+			Description: images.convert(workitem.GetDescription(task)),
+			Estimate:    workitem.GetOriginalEstimate(task),
+			TfsTaskID:   *task.Id,
+			Tags:        workitem.GetTags(task),
+		})
+	}
+
+	sb.WriteString(wiki.RenderTasksTable(pageTasks, func(t *wiki.Task) string {
+		return renderWikiMacro(macros.Task, macros.WorkItemData(workItems[t.TfsTaskID]))
+	}))
+
+	return sb.String()
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+var (
+	publishWikiCmd = &cobra.Command{
+		Use:   "publish <dir>",
+		Short: "Publish directory of Markdown files as wiki page tree",
+		Long: `Publish directory of Markdown files as the tree of wiki pages under the target page.
+Each file becomes a page, each subdirectory becomes a page with content of its index.md or README.md,
+index file of the directory itself becomes the content of the target page.
+Relative links between files become links between pages, referenced images and files are attached.
+Only changed pages are updated, pages of removed files are deleted with --delete.
+If target page title used, space key required.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := publishWikiCommand(args[0])
+			cobra.CheckErr(err)
+		},
+	}
+
+	publishWikiCmdFlagTarget string
+	publishWikiCmdFlagSpace  string
+	publishWikiCmdFlagDelete bool
+)
+
+func init() {
+	wikiCmd.AddCommand(publishWikiCmd)
+
+	publishWikiCmd.Flags().StringVarP(&publishWikiCmdFlagTarget, "target", "t", "", "ID or title of target wiki page")
+	publishWikiCmd.Flags().StringVarP(&publishWikiCmdFlagSpace, "space", "s", "", "Space key of target page")
+	publishWikiCmd.Flags().BoolVarP(&publishWikiCmdFlagDelete, "delete", "", false, "Delete pages of removed files")
+	cobra.CheckErr(publishWikiCmd.MarkFlagRequired("target"))
+}
+
+// publishedWikiPage is the descendant of the target page.
+type publishedWikiPage struct {
+	node     *wiki.PageNode
+	parentID string
+	// property is empty if the page was not published.
+	property wiki.PublishProperty
+}
+
+func publishWikiCommand(dir string) error {
+	api, err := wiki.NewClient()
+	if err != nil {
+		return err
+	}
+
+	target, err := getWikiPage(api, publishWikiCmdFlagTarget, publishWikiCmdFlagSpace)
+	if err != nil {
+		return err
+	}
+
+	tree, err := wiki.ReadPublishTree(dir, target.Title)
+	if err != nil {
+		return err
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Loading wiki pages...")
+	targetTree, err := api.GetPageTree(target.ID)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+
+	published, err := getPublishedWikiPages(api, targetTree)
+	if err != nil {
+		spinner.Fail(err.Error())
+		return err
+	}
+	_ = spinner.Stop()
+
+	byPath := make(map[string]*publishedWikiPage)
+	byTitle := make(map[string]*publishedWikiPage)
+	for _, p := range published {
+		if p.property.Path != "" {
+			byPath[p.property.Path] = p
+		}
+		if p.node.ID != target.ID {
+			byTitle[p.node.Title] = p
+		}
+	}
+
+	// IDs of pages are kept, so children are created under them and reused pages are not deleted
+	pageIDs := make(map[*wiki.PublishPage]string)
+	used := map[string]bool{target.ID: true}
+
+	err = tree.Walk(func(page, parent *wiki.PublishPage) error {
+		if parent == nil {
+			pageIDs[page] = target.ID
+			if page.File == "" {
+				return nil
+			}
+		}
+
+		p, ok := byPath[page.Path]
+		switch {
+		case parent == nil:
+			p = published[target.ID]
+		case !ok:
+			p = byTitle[page.Title]
+		}
+
+		var parentID string
+		if parent != nil {
+			parentID = pageIDs[parent]
+		}
+
+		pageID, status, err := publishWikiPage(api, dir, target, page, parentID, p)
+		if err != nil {
+			pterm.Error.Println(fmt.Sprintf("NOT PUBLISHED %s: %s", page.Path, err.Error()))
+			return err
+		}
+
+		pageIDs[page] = pageID
+		used[pageID] = true
+
+		message := fmt.Sprintf("%s %s (%s)", status, page.Title, page.Path)
+		if status == "UNCHANGED" {
+			pterm.Info.Println(message)
+		} else {
+			pterm.Success.Println(message)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	removed := getRemovedWikiPages(targetTree, published, used)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if !publishWikiCmdFlagDelete {
+		for _, node := range removed {
+			pterm.Warning.Println(fmt.Sprintf("NOT IN DIRECTORY %s (%s), use --delete to delete", node.Title, node.ID))
+		}
+		return nil
+	}
+
+	return deletePublishedWikiPages(api, removed)
+}
+
+// getWikiPage returns the page by ID or by title in the space.
+func getWikiPage(api *wiki.API, pageIDOrTitle, spaceKey string) (*goconfluence.Content, error) {
+	if _, err := strconv.Atoi(pageIDOrTitle); err == nil {
+		return api.GetPageByID(pageIDOrTitle)
+	}
+
+	if spaceKey == "" {
+		return nil, errors.New("space key required when page titles used")
+	}
+	return api.GetPageByTitle(pageIDOrTitle, spaceKey)
+}
+
+// getPublishedWikiPages returns the target page and its descendants with properties of published pages.
+func getPublishedWikiPages(api *wiki.API, tree *wiki.PageNode) (map[string]*publishedWikiPage, error) {
+	pages := make(map[string]*publishedWikiPage)
+
+	var visit func(node *wiki.PageNode, parentID string) error
+	visit = func(node *wiki.PageNode, parentID string) error {
+		p := &publishedWikiPage{node: node, parentID: parentID}
+		_, err := api.GetContentProperty(node.ID, wiki.PublishPropertyKey, &p.property)
+		if err != nil {
+			return err
+		}
+		pages[node.ID] = p
+
+		for _, child := range node.Children {
+			err = visit(child, node.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return pages, visit(tree, "")
+}
+
+// publishWikiPage creates or updates the page if it's changed, returns ID of the page and the publishing status.
+func publishWikiPage(api *wiki.API, dir string, target *goconfluence.Content, page *wiki.PublishPage, parentID string, p *publishedWikiPage) (string, string, error) {
+	if p != nil && p.property.Hash == page.Hash && p.property.Path == page.Path && (parentID == "" || p.parentID == parentID) {
+		return p.node.ID, "UNCHANGED", nil
+	}
+
+	content := &goconfluence.Content{
+		Type:  "page",
+		Title: page.Title,
+		Body: goconfluence.Body{
+			Storage: goconfluence.Storage{
+				Value:          page.Body,
+				Representation: "storage",
+			},
+		},
+		Space: &goconfluence.Space{
+			Key: target.Space.Key,
+		},
+	}
+	if parentID != "" {
+		content.Ancestors = []goconfluence.Ancestor{{ID: parentID}}
+	}
+
+	status := "CREATED"
+	if p != nil {
+		current, err := api.GetContentByID(p.node.ID, goconfluence.ContentQuery{
+			Expand: []string{"version"},
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		content.ID = current.ID
+		content.Version = &goconfluence.Version{
+			Number: current.Version.Number + 1,
+		}
+		status = "UPDATED"
+	}
+
+	var result *goconfluence.Content
+	var err error
+	if content.ID == "" {
+		result, err = api.CreateContent(content)
+	} else {
+		result, err = api.UpdateContent(content)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	err = uploadWikiPageAttachments(api, result.ID, dir, page.Attachments)
+	if err != nil {
+		return "", "", err
+	}
+
+	// the property is written last, so the page is published again if attachments are not uploaded
+	err = api.SetContentProperty(result.ID, wiki.PublishPropertyKey, wiki.PublishProperty{
+		Path: page.Path,
+		Hash: page.Hash,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return result.ID, status, nil
+}
+
+// uploadWikiPageAttachments attaches files to the page, attachments with the same names are updated.
+func uploadWikiPageAttachments(api *wiki.API, pageID, dir string, attachments map[string]string) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	ids, err := api.GetAttachmentIDs(pageID)
+	if err != nil {
+		return err
+	}
+
+	for name, file := range attachments {
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return err
+		}
+
+		if id, ok := ids[name]; ok {
+			_, err = api.UpdateAttachment(pageID, name, id, f)
+		} else {
+			_, err = api.UploadAttachment(pageID, name, f)
+		}
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to attach %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// getRemovedWikiPages returns published pages which files are removed, descendants of removed pages are not returned.
+func getRemovedWikiPages(tree *wiki.PageNode, published map[string]*publishedWikiPage, used map[string]bool) []*wiki.PageNode {
+	var removed []*wiki.PageNode
+
+	var visit func(node *wiki.PageNode)
+	visit = func(node *wiki.PageNode) {
+		if p := published[node.ID]; !used[node.ID] && p != nil && p.property.Path != "" {
+			removed = append(removed, node)
+			return
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(tree)
+
+	return removed
+}
+
+// deletePublishedWikiPages deletes removed pages with their descendants after confirmation,
+// trees are loaded again since published pages could be moved from removed ones.
+func deletePublishedWikiPages(api *wiki.API, removed []*wiki.PageNode) error {
+	var trees []*wiki.PageNode
+	for _, node := range removed {
+		tree, err := api.GetPageTree(node.ID)
+		if err != nil {
+			return err
+		}
+		trees = append(trees, tree)
+	}
+
+	ok, err := confirmDeleteWikiPages(trees)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("canceled by user")
+	}
+
+	for _, tree := range trees {
+		err = deleteWikiPageTree(api, tree)
+		if err != nil {
+			pterm.Error.Println(fmt.Sprintf("NOT DELETED %s (%s): %s", tree.Title, tree.ID, err.Error()))
+			return err
+		}
+		pterm.Success.Println(fmt.Sprintf("DELETED %s (%s)", tree.Title, tree.ID))
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"tasker/wiki"
+
+	"github.com/pterm/pterm"
+)
+
+// wikiUsers resolves names of users mentioned on wiki pages, each user is requested once.
+type wikiUsers struct {
+	api *wiki.API
+
+	mu    sync.Mutex
+	names map[string]string
+}
+
+func newWikiUsers(api *wiki.API) *wikiUsers {
+	return &wikiUsers{
+		api:   api,
+		names: make(map[string]string),
+	}
+}
+
+// resolve returns the name of the user, empty name is returned (and cached) if the user is not found.
+func (u *wikiUsers) resolve(user wiki.StorageUser) string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	query := user.Query()
+	if name, ok := u.names[query]; ok {
+		return name
+	}
+
+	name, err := u.api.GetUserName(user)
+	if err != nil {
+		pterm.Warning.Println(fmt.Sprintf("USER NOT FOUND %s: %s", query, err.Error()))
+	}
+	u.names[query] = name
+	return name
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
@@ -42,8 +43,9 @@ var (
 	deleteWorkItemsCmd = &cobra.Command{
 		Use:   "delete <Work Item ID, ...>",
 		Short: "Delete work items",
-		Long:  "Delete work items by ID.",
-		Args:  cobra.MinimumNArgs(1),
+		Long: `Delete work items by ID.
+Work items are moved to the recycle bin and can be restored by 'tasker restore', use --destroy to delete them permanently.`,
+		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			var workItemIDs []int
 
@@ -61,8 +63,9 @@ var (
 	copyWorkItemCmd = &cobra.Command{
 		Use:   "copy <Work Item ID>",
 		Short: "Copy work item",
-		Long:  "Copy work item by ID.",
-		Args:  cobra.ExactArgs(1),
+		Long: `Copy work item by ID.
+With --recursive flag the whole subtree of child work items is copied preserving hierarchy.`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			workItemID, err := strconv.Atoi(args[0])
 			cobra.CheckErr(err)
@@ -125,9 +128,17 @@ var (
 		},
 	}
 
-	copyWorkItemCmdParentID      int
-	copyWorkItemCmdIterationPath string
-	copyWorkItemCmdAreaPath      string
+	copyWorkItemCmdParentID        int
+	copyWorkItemCmdIterationPath   string
+	copyWorkItemCmdAreaPath        string
+	copyWorkItemCmdDescription     string
+	copyWorkItemCmdDescriptionFile string
+	copyWorkItemCmdHTMLDescription bool
+	copyWorkItemCmdRecursive       bool
+	copyWorkItemCmdTitlePrefix     string
+	copyWorkItemCmdTitleReplace    map[string]string
+	copyWorkItemCmdAttachments     bool
+	copyWorkItemCmdLinks           bool
 
 	queryWorkItemsCmdFlagParent string
 	queryWorkItemsCmdFlagType   string
@@ -136,6 +147,8 @@ var (
 	queryWorkItemsCmdFlagTags   []string
 
 	changeWorkItemsParentCmdParentID int
+
+	deleteWorkItemsCmdFlagDestroy bool
 )
 
 func init() {
@@ -148,7 +161,15 @@ func init() {
 
 	copyWorkItemCmd.Flags().IntVarP(&copyWorkItemCmdParentID, "parent", "p", 0, "Id of parent of new Work Item (if specified, then source added as AffectedBy)")
 	copyWorkItemCmd.Flags().StringVarP(&copyWorkItemCmdIterationPath, "iteration", "i", "", "Iteration Path of new Work Item")
-	copyWorkItemCmd.Flags().StringVarP(&copyWorkItemCmdIterationPath, "area", "a", "", "Area Path of new Work Item")
+	copyWorkItemCmd.Flags().StringVarP(&copyWorkItemCmdAreaPath, "area", "a", "", "Area Path of new Work Item")
+	copyWorkItemCmd.Flags().StringVarP(&copyWorkItemCmdDescription, "description", "d", "", "Description of new Work Item in Markdown (\"-\" for stdin)")
+	copyWorkItemCmd.Flags().StringVarP(&copyWorkItemCmdDescriptionFile, "description-file", "F", "", "Read description of new Work Item from file (\"-\" for stdin)")
+	copyWorkItemCmd.Flags().BoolVarP(&copyWorkItemCmdHTMLDescription, "html", "", false, "Description is HTML, do not convert it from Markdown")
+	copyWorkItemCmd.Flags().BoolVarP(&copyWorkItemCmdRecursive, "recursive", "r", false, "Copy work item with all child work items")
+	copyWorkItemCmd.Flags().StringVar(&copyWorkItemCmdTitlePrefix, "title-prefix", "", "Prefix for titles of new Work Items")
+	copyWorkItemCmd.Flags().StringToStringVar(&copyWorkItemCmdTitleReplace, "title-replace", nil, "Replace substrings in titles of new Work Items in order of replaced substrings, ie \"Feature A=Feature B\"")
+	copyWorkItemCmd.Flags().BoolVar(&copyWorkItemCmdAttachments, "with-attachments", false, "Attach files of source Work Items to new ones")
+	copyWorkItemCmd.Flags().BoolVar(&copyWorkItemCmdLinks, "with-links", false, "Copy links of source Work Items except parent and child ones")
 
 	queryWorkItemsCmd.Flags().StringVarP(&queryWorkItemsCmdFlagParent, "parent", "p", "", "Work items child of specified work item")
 	queryWorkItemsCmd.Flags().StringVarP(&queryWorkItemsCmdFlagType, "type", "t", "", "Work items specified type")
@@ -156,6 +177,8 @@ func init() {
 	queryWorkItemsCmd.Flags().BoolVarP(&queryWorkItemsCmdFlagActive, "active", "a", false, "Work items in active state")
 	queryWorkItemsCmd.Flags().StringSliceVarP(&queryWorkItemsCmdFlagTags, "tag", "", nil, "Work items tag")
 
+	deleteWorkItemsCmd.Flags().BoolVar(&deleteWorkItemsCmdFlagDestroy, "destroy", false, "Delete work items permanently, not to the recycle bin")
+
 	changeWorkItemsParentCmd.Flags().IntVarP(&changeWorkItemsParentCmdParentID, "parent", "p", 0, "ID of new parent work item")
 	cobra.CheckErr(changeWorkItemsParentCmd.MarkFlagRequired("parent"))
 }
@@ -194,34 +217,7 @@ func changeWorkItemsParentCommand(ctx context.Context, workItemIDs []int) error
 			progressbar.UpdateTitle(fmt.Sprintf("Processing %s", workitem.GetTitle(&workItem)))
 		}
 
-		relationIndex := slices.IndexFunc(*workItem.Relations, func(rel workitemtracking.WorkItemRelation) bool {
-			return *rel.Rel == "System.LinkTypes.Hierarchy-Reverse"
-		})
-		if relationIndex == -1 {
-			relationIndex = 0
-		}
-
-		fields := []webapi.JsonPatchOperation{
-			{
-				Op:   &webapi.OperationValues.Remove,
-				Path: ptr.FromStr(fmt.Sprintf("/relations/%d", relationIndex)),
-			},
-			{
-				Op:   &webapi.OperationValues.Add,
-				Path: ptr.FromStr("/relations/-"),
-				Value: workitemtracking.WorkItemRelation{
-					Rel: ptr.FromStr("System.LinkTypes.Hierarchy-Reverse"),
-					Url: newParentWorkItem.Url,
-				},
-			},
-		}
-
-		_, err := a.WiClient.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
-			Id:       workItem.Id,
-			Project:  &a.Project,
-			Document: &fields,
-		})
-
+		err := a.WiClient.SetParent(ctx, &workItem, *newParentWorkItem.Url)
 		if err != nil {
 			return err
 		}
@@ -281,6 +277,10 @@ func closeWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
 }
 
 func copyWorkItemsCommand(ctx context.Context, sourceWorkItemID int) error {
+	if copyWorkItemCmdRecursive {
+		return copyWorkItemTreeCommand(ctx, sourceWorkItemID)
+	}
+
 	spinner, _ := pterm.DefaultSpinner.Start()
 	defer func() {
 		_ = spinner.Stop()
@@ -291,11 +291,60 @@ func copyWorkItemsCommand(ctx context.Context, sourceWorkItemID int) error {
 		return err
 	}
 
-	sourceWorkItem, err := a.WiClient.Get(ctx, sourceWorkItemID)
+	sourceWorkItem, err := a.WiClient.GetExpanded(ctx, sourceWorkItemID)
+	if err != nil {
+		return err
+	}
+
+	relations, err := getCopyRootRelations(ctx, a, sourceWorkItem)
 	if err != nil {
 		return err
 	}
 
+	description, err := getDescription(copyWorkItemCmdDescription, copyWorkItemCmdDescriptionFile, workitem.GetTitle(sourceWorkItem), copyWorkItemCmdHTMLDescription, false)
+	if err != nil {
+		return err
+	}
+
+	task, err := copyWorkItem(ctx, a, sourceWorkItem, relations, description)
+	printCreateTaskResult(task, err, spinner)
+	openInBrowser(task)
+
+	return err
+}
+
+// getCopyRootRelations links copy to the parent specified by user and to the source as affected by,
+// otherwise the copy becomes a child of the source.
+func getCopyRootRelations(ctx context.Context, a *tfs.API, sourceWorkItem *workitemtracking.WorkItem) ([]*workitem.Relation, error) {
+	if copyWorkItemCmdParentID == 0 {
+		return []*workitem.Relation{
+			{
+				URL:  *sourceWorkItem.Url,
+				Type: workitem.ParentLinkType,
+			},
+		}, nil
+	}
+
+	parent, err := a.WiClient.Get(ctx, copyWorkItemCmdParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*workitem.Relation{
+		{
+			URL:  *parent.Url,
+			Type: workitem.ParentLinkType,
+		},
+		{
+			URL:  *sourceWorkItem.Url,
+			Type: workitem.AffectedByLinkType,
+		},
+	}, nil
+}
+
+// copyWorkItem copies work item applying area, iteration and title overrides.
+// Attachments and links of the source are copied if requested by user.
+func copyWorkItem(ctx context.Context, a *tfs.API, sourceWorkItem *workitemtracking.WorkItem, relations []*workitem.Relation, description string) (*workitemtracking.WorkItem, error) {
 	areaPath := copyWorkItemCmdAreaPath
 	if areaPath == "" {
 		areaPath = workitem.GetAreaPath(sourceWorkItem)
@@ -306,35 +355,72 @@ func copyWorkItemsCommand(ctx context.Context, sourceWorkItemID int) error {
 		iterationPath = workitem.GetIterationPath(sourceWorkItem)
 	}
 
+	fields := []*workitem.Field{
+		{
+			Path:  ptr.FromStr("/fields/System.Title"),
+			Value: getCopyTitle(sourceWorkItem),
+		},
+	}
+	if description != "" {
+		fields = append(fields, &workitem.Field{
+			Path:  ptr.FromStr("/fields/System.Description"),
+			Value: description,
+		})
+	}
+
+	relations = append(relations, getCopiedRelations(sourceWorkItem)...)
+
+	tags := workitem.GetTags(sourceWorkItem)
+	return a.WiClient.CopyWithFields(ctx, sourceWorkItem, areaPath, iterationPath, relations, tags, fields)
+}
+
+func getCopyTitle(sourceWorkItem *workitemtracking.WorkItem) string {
+	title := workitem.GetTitle(sourceWorkItem)
+	// replacements are applied in order of replaced substrings, so overlapping ones give the same title each time
+	oldValues := lo.Keys(copyWorkItemCmdTitleReplace)
+	slices.Sort(oldValues)
+	for _, oldValue := range oldValues {
+		title = strings.ReplaceAll(title, oldValue, copyWorkItemCmdTitleReplace[oldValue])
+	}
+	return copyWorkItemCmdTitlePrefix + title
+}
+
+// getCopiedRelations returns attachments and non-hierarchy links of the source to be added to its copy.
+func getCopiedRelations(sourceWorkItem *workitemtracking.WorkItem) []*workitem.Relation {
+	if sourceWorkItem.Relations == nil {
+		return nil
+	}
+
 	var relations []*workitem.Relation
+	for _, relation := range *sourceWorkItem.Relations {
+		if relation.Rel == nil || relation.Url == nil {
+			continue
+		}
 
-	if copyWorkItemCmdParentID != 0 {
-		parent, err := a.WiClient.Get(ctx, copyWorkItemCmdParentID)
-		if err != nil {
-			return err
+		switch *relation.Rel {
+		case workitem.ParentLinkType, workitem.ChildLinkType, workitem.ArtifactLinkType:
+			continue
+		case workitem.AttachedFileLinkType:
+			if !copyWorkItemCmdAttachments {
+				continue
+			}
+		default:
+			if !copyWorkItemCmdLinks {
+				continue
+			}
 		}
 
-		relations = append(relations, &workitem.Relation{
-			URL:  *parent.Url,
-			Type: "System.LinkTypes.Hierarchy-Reverse",
-		})
-		relations = append(relations, &workitem.Relation{
-			URL:  *sourceWorkItem.Url,
-			Type: "Microsoft.VSTS.Common.Affects-Reverse",
-		})
-	} else {
-		relations = append(relations, &workitem.Relation{
-			URL:  *sourceWorkItem.Url,
-			Type: "System.LinkTypes.Hierarchy-Reverse",
-		})
+		copied := &workitem.Relation{
+			URL:  *relation.Url,
+			Type: *relation.Rel,
+		}
+		if relation.Attributes != nil {
+			copied.Attributes = lo.PickByKeys(*relation.Attributes, []string{"name", "comment"})
+		}
+		relations = append(relations, copied)
 	}
 
-	tags := workitem.GetTags(sourceWorkItem)
-	task, err := a.WiClient.Copy(ctx, sourceWorkItem, areaPath, iterationPath, relations, tags)
-	printCreateTaskResult(task, err, spinner)
-	openInBrowser(task)
-
-	return err
+	return relations
 }
 
 func getWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
@@ -450,6 +536,17 @@ func deleteWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
 		return err
 	}
 
+	if deleteWorkItemsCmdFlagDestroy {
+		ok, err := confirmDestroyWorkItems(ctx, a, workItemIDs)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errors.New("canceled by user")
+		}
+	}
+
 	progressbar, err := pterm.DefaultProgressbar.WithTitle("Processing...").WithTotal(len(workItemIDs)).WithRemoveWhenDone().Start()
 	if err == nil {
 		defer func() {
@@ -464,7 +561,7 @@ func deleteWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
 			progressbar.UpdateTitle(fmt.Sprintf("Processing %d", workItemID))
 		}
 
-		err := a.WiClient.Delete(ctx, workItemID)
+		err := a.WiClient.Delete(ctx, workItemID, deleteWorkItemsCmdFlagDestroy)
 		if err != nil {
 			return err
 		}
@@ -476,3 +573,28 @@ func deleteWorkItemsCommand(ctx context.Context, workItemIDs []int) error {
 
 	return nil
 }
+
+func confirmDestroyWorkItems(ctx context.Context, a *tfs.API, workItemIDs []int) (bool, error) {
+	workItems, err := a.WiClient.GetList(ctx, workItemIDs)
+	if err != nil {
+		return false, err
+	}
+
+	tableData := [][]string{{"ID", "Type", "Title", "State"}}
+	for i := range workItems {
+		tableData = append(tableData, []string{
+			strconv.Itoa(*workItems[i].Id),
+			workitem.GetType(&workItems[i]),
+			workitem.GetTitle(&workItems[i]),
+			workitem.GetState(&workItems[i]),
+		})
+	}
+
+	err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	if err != nil {
+		return false, err
+	}
+
+	pterm.Warning.Println("These work items will be destroyed permanently, they can not be restored.")
+	return requestEnterConfirmation()
+}
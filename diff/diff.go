@@ -0,0 +1,67 @@
+package diff
+
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines returns line-based diff of old and new texts computed by longest common subsequence.
+func Lines(old, new []string) []Line {
+	// lcs[i][j] is the length of common subsequence of old[i:] and new[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result []Line
+	i, j := 0, 0
+	for i < len(old) && j < len(new) {
+		switch {
+		case old[i] == new[j]:
+			result = append(result, Line{Op: Equal, Text: old[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, Line{Op: Delete, Text: old[i]})
+			i++
+		default:
+			result = append(result, Line{Op: Insert, Text: new[j]})
+			j++
+		}
+	}
+	for ; i < len(old); i++ {
+		result = append(result, Line{Op: Delete, Text: old[i]})
+	}
+	for ; j < len(new); j++ {
+		result = append(result, Line{Op: Insert, Text: new[j]})
+	}
+
+	return result
+}
+
+// Changed reports whether the diff contains any inserted or deleted line.
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
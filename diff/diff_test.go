@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      []string
+		new      []string
+		expected []Line
+		changed  bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "equal",
+			old:      []string{"a", "b"},
+			new:      []string{"a", "b"},
+			expected: []Line{{Op: Equal, Text: "a"}, {Op: Equal, Text: "b"}},
+		},
+		{
+			name:     "insert",
+			old:      []string{"a", "c"},
+			new:      []string{"a", "b", "c"},
+			expected: []Line{{Op: Equal, Text: "a"}, {Op: Insert, Text: "b"}, {Op: Equal, Text: "c"}},
+			changed:  true,
+		},
+		{
+			name:     "delete",
+			old:      []string{"a", "b", "c"},
+			new:      []string{"a", "c"},
+			expected: []Line{{Op: Equal, Text: "a"}, {Op: Delete, Text: "b"}, {Op: Equal, Text: "c"}},
+			changed:  true,
+		},
+		{
+			name:     "replace",
+			old:      []string{"a", "b", "c"},
+			new:      []string{"a", "x", "c"},
+			expected: []Line{{Op: Equal, Text: "a"}, {Op: Delete, Text: "b"}, {Op: Insert, Text: "x"}, {Op: Equal, Text: "c"}},
+			changed:  true,
+		},
+		{
+			name:     "old empty",
+			new:      []string{"a", "b"},
+			expected: []Line{{Op: Insert, Text: "a"}, {Op: Insert, Text: "b"}},
+			changed:  true,
+		},
+		{
+			name:     "new empty",
+			old:      []string{"a", "b"},
+			expected: []Line{{Op: Delete, Text: "a"}, {Op: Delete, Text: "b"}},
+			changed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := Lines(tt.old, tt.new)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.changed, Changed(actual))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(
+		html.WithUnsafe(),
+		html.WithXHTML(),
+	),
+)
+
+// ToHTML converts CommonMark/GFM markdown to HTML.
+func ToHTML(source string) (string, error) {
+	var result bytes.Buffer
+	err := converter.Convert([]byte(source), &result)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
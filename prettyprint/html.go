@@ -0,0 +1,70 @@
+package prettyprint
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var blankLinesRegexp = regexp.MustCompile(`\n{3,}`)
+
+// HTMLText converts HTML fragment into plain text keeping paragraphs, line breaks and list items.
+func HTMLText(source string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(source), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return source
+	}
+
+	var sb strings.Builder
+	for _, node := range nodes {
+		writeHTMLText(&sb, node)
+	}
+
+	text := blankLinesRegexp.ReplaceAllString(sb.String(), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+func writeHTMLText(sb *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		sb.WriteString(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch node.Data {
+	case "br":
+		sb.WriteString("\n")
+		return
+	case "li":
+		sb.WriteString("\n- ")
+	case "td", "th":
+		sb.WriteString(" | ")
+	case "p", "div", "tr", "ul", "ol", "pre", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+		sb.WriteString("\n")
+	case "img":
+		for _, attr := range node.Attr {
+			if attr.Key == "src" {
+				sb.WriteString("[image " + attr.Val + "]")
+			}
+		}
+		return
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeHTMLText(sb, child)
+	}
+
+	switch node.Data {
+	case "p", "div", "ul", "ol", "pre", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+		sb.WriteString("\n")
+	}
+}
@@ -15,3 +15,10 @@ func FromBool(value bool) *bool {
 func From[T any](value T) *T {
 	return &value
 }
+
+func ToStr(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -16,7 +16,26 @@ type Task interface {
 	SetTagsString(tags string)
 }
 
+// AssignedTask is a Task of existing work item previewed with its assignee, e.g. when tasks are rolled over.
+type AssignedTask interface {
+	Task
+	GetAssignedTo() string
+}
+
+// ChangedTask is a Task previewed with the mark of its change since the previous version, e.g. when wiki page is synced with --diff.
+type ChangedTask interface {
+	Task
+	// GetChange returns "+" for added task, "~" for changed one and empty string for not changed one.
+	GetChange() string
+}
+
 type Table interface {
 	GetTasks() []Task
 	SetTask(tsk Task, index int)
 }
+
+// NamedTable is a Table shown with a caption, e.g. when tasks of several wiki pages are previewed together.
+type NamedTable interface {
+	Table
+	GetName() string
+}
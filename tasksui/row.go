@@ -13,6 +13,8 @@ type uiRow struct {
 	rowNumber        int
 	titleWidth       int
 	descriptionWidth int
+	// assignedWidth is the width of Assigned To column, the column is not shown if it's 0.
+	assignedWidth int
 }
 
 func (r *uiRow) draw() {
@@ -25,20 +27,46 @@ func (r *uiRow) draw() {
 		tfsTaskID = fmt.Sprintf("%d", r.task.GetTfsTaskID())
 	}
 
-	r.table.SetCell(r.rowNumber, 0, tview.NewTableCell(fmt.Sprintf("%d", r.rowNumber)).SetTextColor(tcell.ColorDimGray))
-	r.table.SetCell(r.rowNumber, 1, tview.NewTableCell(cutString(r.task.GetTitle(), r.titleWidth, true)))
-	r.table.SetCell(r.rowNumber, 2, tview.NewTableCell(cutString(r.task.GetDescription(), r.descriptionWidth, true)))
-	r.table.SetCell(r.rowNumber, 3, tview.NewTableCell(fmt.Sprintf("%v", r.task.GetEstimate())))
-	r.table.SetCell(r.rowNumber, 4, tview.NewTableCell(tfsTaskID))
+	number := tview.NewTableCell(fmt.Sprintf("%d", r.rowNumber)).SetTextColor(tcell.ColorDimGray)
+	if t, ok := r.task.(ChangedTask); ok {
+		switch change := t.GetChange(); change {
+		case "+":
+			number.SetText(change + number.Text).SetTextColor(tcell.ColorGreen)
+		case "~":
+			number.SetText(change + number.Text).SetTextColor(tcell.ColorYellow)
+		}
+	}
+
+	cells := []*tview.TableCell{
+		number,
+		tview.NewTableCell(cutString(r.task.GetTitle(), r.titleWidth, true)),
+		tview.NewTableCell(cutString(r.task.GetDescription(), r.descriptionWidth, true)),
+	}
+	if r.assignedWidth > 0 {
+		assignedTo := ""
+		if t, ok := r.task.(AssignedTask); ok {
+			assignedTo = t.GetAssignedTo()
+		}
+		cells = append(cells, tview.NewTableCell(cutString(assignedTo, r.assignedWidth, true)))
+	}
+	cells = append(cells,
+		tview.NewTableCell(fmt.Sprintf("%v", r.task.GetEstimate())),
+		tview.NewTableCell(tfsTaskID),
+	)
+
+	for i, cell := range cells {
+		r.table.SetCell(r.rowNumber, i, cell)
+	}
 }
 
-func newRow(table *tview.Table, task Task, rowNumber, titleWidth, descriptionWidth int) *uiRow {
+func newRow(table *tview.Table, task Task, rowNumber, titleWidth, descriptionWidth, assignedWidth int) *uiRow {
 	r := uiRow{
 		table:            table,
 		task:             task,
 		rowNumber:        rowNumber,
 		titleWidth:       titleWidth,
 		descriptionWidth: descriptionWidth,
+		assignedWidth:    assignedWidth,
 	}
 
 	return &r
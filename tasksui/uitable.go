@@ -2,10 +2,12 @@ package tasksui
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/pterm/pterm"
 	"github.com/rivo/tview"
+	"github.com/samber/lo"
 )
 
 type uiTable struct {
@@ -87,18 +89,32 @@ func (u *ui) newTable(table Table) *uiTable {
 			return act, ev
 		})
 
+	if named, ok := table.(NamedTable); ok {
+		view.SetBorder(true).SetTitle(" " + named.GetName() + " ")
+	}
+
 	u.tabbedItems = append(u.tabbedItems, view)
 
 	titleWidth, descriptionWidth := getColumnsWidth()
-	ut.createRows(titleWidth, descriptionWidth)
+	assignedWidth := 0
+	if lo.SomeBy(tasks, func(t Task) bool { _, ok := t.(AssignedTask); return ok }) {
+		assignedWidth = min(assignedToColumnWidth, descriptionWidth/2)
+		descriptionWidth -= assignedWidth + columnSeparatorWidth
+	}
+	ut.createRows(titleWidth, descriptionWidth, assignedWidth)
 
 	return &ut
 }
 
-func (ut *uiTable) createRows(titleWidth, descriptionWidth int) {
+func (ut *uiTable) createRows(titleWidth, descriptionWidth, assignedWidth int) {
 
 	var totalEstimate float32
 	headers := []string{"#", "Title", "Description", "Estimate", "TFS"}
+	footerGap := []string{""}
+	if assignedWidth > 0 {
+		headers = slices.Insert(headers, 3, "Assigned To")
+		footerGap = append(footerGap, "")
+	}
 
 	row := 0
 
@@ -110,23 +126,29 @@ func (ut *uiTable) createRows(titleWidth, descriptionWidth int) {
 
 	for _, task := range ut.tasks {
 		totalEstimate += task.GetEstimate()
-		ut.rows = append(ut.rows, newRow(ut.view, task, row, titleWidth, descriptionWidth))
+		ut.rows = append(ut.rows, newRow(ut.view, task, row, titleWidth, descriptionWidth, assignedWidth))
 		row++
 	}
 
-	footers := []string{"", "∑", "", fmt.Sprintf("%v", totalEstimate), ""}
+	footers := slices.Concat([]string{"", "∑"}, footerGap, []string{fmt.Sprintf("%v", totalEstimate), ""})
 	for i, footer := range footers {
 		ut.view.SetCell(row, i, tview.NewTableCell(footer).SetTextColor(tcell.ColorYellow))
 	}
 }
 
+const (
+	// assignedToColumnWidth is the max width of Assigned To column taken from Description column.
+	assignedToColumnWidth = 25
+	columnSeparatorWidth  = 3
+)
+
 func getColumnsWidth() (int, int) {
 	/*
 		#  | Title | Description  | Estimate | TFS
 		01 | *     | *            | 3        | 12345
 	*/
 
-	colSep := 3
+	colSep := columnSeparatorWidth
 	numberCol := 2
 	estimateCol := 8
 	tfsCol := 5
@@ -3,6 +3,9 @@ package tfs
 import (
 	"context"
 	"errors"
+	"net/url"
+	"strconv"
+	"strings"
 	"tasker/tfs/connection"
 	"tasker/tfs/identity"
 	"tasker/tfs/work"
@@ -47,7 +50,12 @@ func (a *API) GetCurrentIteration(ctx context.Context) (*azurework.TeamSettingsI
 	return work.GetCurrentIteration(ctx, a.Conn, a.Project, a.Team)
 }
 
-func (a *API) CreateWorkItem(ctx context.Context, workitemType, title, description string, estimate float32, parentID int, relations []*workitem.Relation, tags []string, parentNamePattern string, assign, currentIter bool) (*workitemtracking.WorkItem, error) {
+// GetWorkItemWebURL returns URL of the work item web page, it does not require the work item to be fetched with links.
+func (a *API) GetWorkItemWebURL(workItemID int) string {
+	return strings.TrimRight(a.Conn.BaseUrl, "/") + "/" + url.PathEscape(a.Project) + "/_workitems/edit/" + strconv.Itoa(workItemID)
+}
+
+func (a *API) CreateWorkItem(ctx context.Context, workitemType, title, description string, estimate float32, parentID int, relations []*workitem.Relation, tags []string, parentNamePattern string, assign, currentIter bool, fields ...*workitem.Field) (*workitemtracking.WorkItem, error) {
 	var err error
 	var parent *workitemtracking.WorkItem
 	var user string
@@ -60,18 +68,14 @@ func (a *API) CreateWorkItem(ctx context.Context, workitemType, title, descripti
 		user = userIdentity.DisplayName
 	}
 
-	if parentID > 0 {
-		parent, err = a.WiClient.Get(ctx, parentID)
-	} else {
-		parent, err = a.findCurrentRequirementByPattern(ctx, parentNamePattern)
-	}
+	parent, err = a.FindParent(ctx, parentID, parentNamePattern)
 	if err != nil {
 		return nil, err
 	}
 
 	parentRelation := workitem.Relation{
 		URL:  *parent.Url,
-		Type: "System.LinkTypes.Hierarchy-Reverse",
+		Type: workitem.ParentLinkType,
 	}
 	relations = append(relations, &parentRelation)
 
@@ -94,9 +98,9 @@ func (a *API) CreateWorkItem(ctx context.Context, workitemType, title, descripti
 
 	var workitem *workitemtracking.WorkItem
 	if workitemType == "Requirement" {
-		workitem, err = a.WiClient.CreateRequirement(ctx, "Development", title, description, areaPath, iterationPath, estimate, 1, relations, tags)
+		workitem, err = a.WiClient.CreateRequirement(ctx, "Development", title, description, areaPath, iterationPath, estimate, 1, relations, tags, fields...)
 	} else {
-		workitem, err = a.WiClient.CreateTask(ctx, title, description, areaPath, iterationPath, estimate, relations, tags)
+		workitem, err = a.WiClient.CreateTask(ctx, title, description, areaPath, iterationPath, estimate, relations, tags, fields...)
 	}
 	if err != nil {
 		return nil, err
@@ -112,6 +116,14 @@ func (a *API) CreateWorkItem(ctx context.Context, workitemType, title, descripti
 	return workitem, nil
 }
 
+// FindParent gets parent work item by ID or, if ID is not specified, finds it by name pattern in current sprints.
+func (a *API) FindParent(ctx context.Context, parentID int, parentNamePattern string) (*workitemtracking.WorkItem, error) {
+	if parentID > 0 {
+		return a.WiClient.Get(ctx, parentID)
+	}
+	return a.findCurrentRequirementByPattern(ctx, parentNamePattern)
+}
+
 func (a *API) findActiveRequirementByPattern(ctx context.Context, namePattern string) (*workitemtracking.WorkItem, error) {
 	requirement, err := a.WiClient.FindRequirement(ctx, namePattern, "", "Active")
 	if err != nil {
@@ -147,17 +159,17 @@ func (a *API) findCurrentRequirementByPattern(ctx context.Context, namePattern s
 	return nil, errors.New("active requirement with name contains '" + namePattern + "' not found in current and previous sprints")
 }
 
-func (a *API) CreateChildTask(ctx context.Context, title, description string, estimate float32, parent *workitemtracking.WorkItem, tags []string) (*workitemtracking.WorkItem, error) {
+func (a *API) CreateChildTask(ctx context.Context, title, description string, estimate float32, parent *workitemtracking.WorkItem, tags []string, fields ...*workitem.Field) (*workitemtracking.WorkItem, error) {
 	iterationPath := workitem.GetIterationPath(parent)
 	areaPath := workitem.GetAreaPath(parent)
 	relations := []*workitem.Relation{
 		{
 			URL:  *parent.Url,
-			Type: "System.LinkTypes.Hierarchy-Reverse",
+			Type: workitem.ParentLinkType,
 		},
 	}
 
-	return a.WiClient.CreateTask(ctx, title, description, areaPath, iterationPath, estimate, relations, tags)
+	return a.WiClient.CreateTask(ctx, title, description, areaPath, iterationPath, estimate, relations, tags, fields...)
 }
 
 func (a *API) CreateChildRequirement(ctx context.Context, requirementType, title, description string, estimate, priority float32, parent *workitemtracking.WorkItem, tags []string) (*workitemtracking.WorkItem, error) {
@@ -167,7 +179,7 @@ func (a *API) CreateChildRequirement(ctx context.Context, requirementType, title
 	relations := []*workitem.Relation{
 		{
 			URL:  *parent.Url,
-			Type: "System.LinkTypes.Hierarchy-Reverse",
+			Type: workitem.ParentLinkType,
 		},
 	}
 
@@ -10,6 +10,11 @@ import (
 	"github.com/spf13/viper"
 )
 
+var (
+	ErrNotFound  = errors.New("user identity not found")
+	ErrNotUnique = errors.New("user filter not unique")
+)
+
 type Identity struct {
 	Id          string
 	DisplayName string
@@ -18,6 +23,11 @@ type Identity struct {
 func Get(ctx context.Context, conn *azuredevops.Connection) (*Identity, error) {
 	userFilter := viper.GetString("tfsUserFilter")
 
+	return Find(ctx, conn, userFilter)
+}
+
+// Find looks up a single identity by account name, e-mail or display name.
+func Find(ctx context.Context, conn *azuredevops.Connection, filter string) (*Identity, error) {
 	client, err := identity.NewClient(ctx, conn)
 	if err != nil {
 		return nil, err
@@ -25,7 +35,7 @@ func Get(ctx context.Context, conn *azuredevops.Connection) (*Identity, error) {
 
 	identities, err := client.ReadIdentities(ctx, identity.ReadIdentitiesArgs{
 		SearchFilter:    ptr.FromStr("General"),
-		FilterValue:     ptr.FromStr(userFilter),
+		FilterValue:     ptr.FromStr(filter),
 		QueryMembership: &identity.QueryMembershipValues.None,
 	})
 	if err != nil {
@@ -33,11 +43,11 @@ func Get(ctx context.Context, conn *azuredevops.Connection) (*Identity, error) {
 	}
 
 	if identities == nil || len(*identities) == 0 {
-		return nil, errors.New("user identity not found")
+		return nil, ErrNotFound
 	}
 
 	if len(*identities) > 1 {
-		return nil, errors.New("user filter not unique")
+		return nil, ErrNotUnique
 	}
 
 	identity := (*identities)[0]
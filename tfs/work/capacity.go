@@ -0,0 +1,136 @@
+package work
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/work"
+)
+
+var defaultWorkingDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+
+func GetTeamSettings(ctx context.Context, conn *azuredevops.Connection, project, team string) (*work.TeamSetting, error) {
+	client, err := work.NewClient(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetTeamSettings(ctx, work.GetTeamSettingsArgs{
+		Project: &project,
+		Team:    &team,
+	})
+}
+
+func GetCapacities(ctx context.Context, conn *azuredevops.Connection, project, team string, iterationID uuid.UUID) (*[]work.TeamMemberCapacityIdentityRef, error) {
+	client, err := work.NewClient(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetCapacitiesWithIdentityRef(ctx, work.GetCapacitiesWithIdentityRefArgs{
+		Project:     &project,
+		Team:        &team,
+		IterationId: &iterationID,
+	})
+}
+
+func GetTeamDaysOff(ctx context.Context, conn *azuredevops.Connection, project, team string, iterationID uuid.UUID) ([]work.DateRange, error) {
+	client, err := work.NewClient(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	daysOff, err := client.GetTeamDaysOff(ctx, work.GetTeamDaysOffArgs{
+		Project:     &project,
+		Team:        &team,
+		IterationId: &iterationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if daysOff.DaysOff == nil {
+		return nil, nil
+	}
+
+	return *daysOff.DaysOff, nil
+}
+
+// FindIteration looks up iteration by name or path, the current iteration is returned when name is empty.
+func FindIteration(iterations *[]work.TeamSettingsIteration, name string) *work.TeamSettingsIteration {
+	if name == "" {
+		return FindCurrentIteration(iterations)
+	}
+
+	for i := range *iterations {
+		iteration := &(*iterations)[i]
+		if strings.EqualFold(*iteration.Name, name) || strings.EqualFold(*iteration.Path, name) {
+			return iteration
+		}
+	}
+	return nil
+}
+
+func FindNextIteration(iterations *[]work.TeamSettingsIteration, iteration *work.TeamSettingsIteration) *work.TeamSettingsIteration {
+	for i := 0; i < len(*iterations)-1; i++ {
+		if *(*iterations)[i].Id == *iteration.Id {
+			return &(*iterations)[i+1]
+		}
+	}
+	return nil
+}
+
+// WorkingDays returns the iteration dates which are working days of the team and not in any of days off ranges.
+func WorkingDays(iteration *work.TeamSettingsIteration, workingDays []string, daysOff ...[]work.DateRange) []time.Time {
+	if iteration.Attributes == nil || iteration.Attributes.StartDate == nil || iteration.Attributes.FinishDate == nil {
+		return nil
+	}
+
+	if len(workingDays) == 0 {
+		workingDays = defaultWorkingDays
+	}
+
+	var days []time.Time
+	start := truncateToDay(iteration.Attributes.StartDate.Time)
+	finish := truncateToDay(iteration.Attributes.FinishDate.Time)
+	for day := start; !day.After(finish); day = day.AddDate(0, 0, 1) {
+		if !isWorkingDay(day, workingDays) || isDayOff(day, daysOff...) {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	return days
+}
+
+func isWorkingDay(day time.Time, workingDays []string) bool {
+	weekday := day.Weekday().String()
+	for _, wd := range workingDays {
+		if strings.EqualFold(wd, weekday) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDayOff(day time.Time, daysOff ...[]work.DateRange) bool {
+	for _, ranges := range daysOff {
+		for _, r := range ranges {
+			if r.Start == nil || r.End == nil {
+				continue
+			}
+			if !day.Before(truncateToDay(r.Start.Time)) && !day.After(truncateToDay(r.End.Time)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
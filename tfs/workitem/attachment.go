@@ -0,0 +1,153 @@
+package workitem
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"tasker/ptr"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+type Attachment struct {
+	ID      uuid.UUID
+	Name    string
+	URL     string
+	Size    int
+	Comment string
+}
+
+func (api *Client) UploadAttachment(ctx context.Context, filePath string) (*workitemtracking.AttachmentReference, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return api.UploadAttachmentContent(ctx, filepath.Base(filePath), file)
+}
+
+// UploadAttachmentContent uploads the content as attachment with the file name, the attachment is not linked to any work item.
+func (api *Client) UploadAttachmentContent(ctx context.Context, fileName string, content io.Reader) (*workitemtracking.AttachmentReference, error) {
+	return api.CreateAttachment(ctx, workitemtracking.CreateAttachmentArgs{
+		UploadStream: content,
+		Project:      &api.project,
+		FileName:     &fileName,
+	})
+}
+
+// AddAttachments uploads files and attaches them to the work item.
+func (api *Client) AddAttachments(ctx context.Context, workItemID int, filePaths []string) ([]Attachment, error) {
+	var attachments []Attachment
+	var document []webapi.JsonPatchOperation
+
+	for _, filePath := range filePaths {
+		ref, err := api.UploadAttachment(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		name := filepath.Base(filePath)
+		attachments = append(attachments, Attachment{
+			ID:   *ref.Id,
+			Name: name,
+			URL:  *ref.Url,
+		})
+
+		document = append(document, webapi.JsonPatchOperation{
+			Op:   &webapi.OperationValues.Add,
+			Path: ptr.FromStr("/relations/-"),
+			Value: workitemtracking.WorkItemRelation{
+				Rel: ptr.FromStr(AttachedFileLinkType),
+				Url: ref.Url,
+				Attributes: &map[string]any{
+					"name": name,
+				},
+			},
+		})
+	}
+
+	if len(document) == 0 {
+		return nil, nil
+	}
+
+	_, err := api.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id:       ptr.FromInt(workItemID),
+		Project:  &api.project,
+		Document: &document,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (api *Client) DownloadAttachment(ctx context.Context, attachment Attachment, w io.Writer) error {
+	content, err := api.GetAttachmentContent(ctx, workitemtracking.GetAttachmentContentArgs{
+		Id:       &attachment.ID,
+		Project:  &api.project,
+		FileName: &attachment.Name,
+		Download: ptr.FromBool(true),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// GetAttachments returns attachments of work item, the work item must be fetched with relations.
+func GetAttachments(w *workitemtracking.WorkItem) []Attachment {
+	if w.Relations == nil {
+		return nil
+	}
+
+	var attachments []Attachment
+	for _, relation := range *w.Relations {
+		if relation.Rel == nil || *relation.Rel != AttachedFileLinkType || relation.Url == nil {
+			continue
+		}
+
+		id, err := uuid.Parse(path.Base(*relation.Url))
+		if err != nil {
+			continue
+		}
+
+		attachment := Attachment{
+			ID:  id,
+			URL: *relation.Url,
+		}
+
+		if relation.Attributes != nil {
+			attributes := *relation.Attributes
+			if name, ok := attributes["name"].(string); ok {
+				attachment.Name = name
+			}
+			if comment, ok := attributes["comment"].(string); ok {
+				attachment.Comment = comment
+			}
+			if size, ok := attributes["resourceSize"].(float64); ok {
+				attachment.Size = int(size)
+			}
+		}
+
+		if attachment.Name == "" {
+			attachment.Name = id.String()
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments
+}
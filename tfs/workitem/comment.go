@@ -0,0 +1,77 @@
+package workitem
+
+import (
+	"context"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+type Comment struct {
+	ID     int
+	Author string
+	Date   time.Time
+	Text   string
+}
+
+// PostComment adds HTML comment to the work item discussion.
+func (api *Client) PostComment(ctx context.Context, workItemID int, text string) (*Comment, error) {
+	comment, err := api.AddComment(ctx, workitemtracking.AddCommentArgs{
+		Request: &workitemtracking.CommentCreate{
+			Text: &text,
+		},
+		Project:    &api.project,
+		WorkItemId: &workItemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newComment(comment), nil
+}
+
+// GetDiscussion returns all comments of the work item from oldest to newest.
+func (api *Client) GetDiscussion(ctx context.Context, workItemID int) ([]Comment, error) {
+	var comments []Comment
+	var continuationToken *string
+
+	for {
+		list, err := api.GetComments(ctx, workitemtracking.GetCommentsArgs{
+			Project:           &api.project,
+			WorkItemId:        &workItemID,
+			ContinuationToken: continuationToken,
+			Order:             &workitemtracking.CommentSortOrderValues.Asc,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if list.Comments != nil {
+			for i := range *list.Comments {
+				comments = append(comments, *newComment(&(*list.Comments)[i]))
+			}
+		}
+
+		if list.ContinuationToken == nil || *list.ContinuationToken == "" {
+			return comments, nil
+		}
+		continuationToken = list.ContinuationToken
+	}
+}
+
+func newComment(comment *workitemtracking.Comment) *Comment {
+	result := &Comment{}
+	if comment.Id != nil {
+		result.ID = *comment.Id
+	}
+	if comment.CreatedBy != nil && comment.CreatedBy.DisplayName != nil {
+		result.Author = *comment.CreatedBy.DisplayName
+	}
+	if comment.CreatedDate != nil {
+		result.Date = comment.CreatedDate.Time
+	}
+	if comment.Text != nil {
+		result.Text = *comment.Text
+	}
+	return result
+}
@@ -0,0 +1,300 @@
+package workitem
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tasker/ptr"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+const (
+	ParentLinkType       = "System.LinkTypes.Hierarchy-Reverse"
+	ChildLinkType        = "System.LinkTypes.Hierarchy-Forward"
+	RelatedLinkType      = "System.LinkTypes.Related"
+	AffectedByLinkType   = "Microsoft.VSTS.Common.Affects-Reverse"
+	ArtifactLinkType     = "ArtifactLink"
+	HyperlinkType        = "Hyperlink"
+	AttachedFileLinkType = "AttachedFile"
+)
+
+var (
+	workItemURLRegexp = regexp.MustCompile(`(?i)/workItems/(\d+)$`)
+	artifactURLRegexp = regexp.MustCompile(`(?i)^vstfs:///Git/(Commit|PullRequestId|Ref)/(.+)$`)
+)
+
+type LinkType struct {
+	// Name is a friendly name of the link type, like "parent" or "affected-by".
+	Name          string
+	DisplayName   string
+	ReferenceName string
+	// WorkItemLink is true for links between work items, false for commits, hyperlinks, attachments etc.
+	WorkItemLink bool
+}
+
+type Link struct {
+	Type    LinkType
+	URL     string
+	Comment string
+	// WorkItemID is set for links to work items.
+	WorkItemID int
+	// Artifact is human-readable description of git commit, pull request or branch.
+	Artifact string
+}
+
+// GetLinkTypes returns relation types available on the server.
+func (api *Client) GetLinkTypes(ctx context.Context) ([]LinkType, error) {
+	relationTypes, err := api.GetRelationTypes(ctx, workitemtracking.GetRelationTypesArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	var linkTypes []LinkType
+	for _, relationType := range *relationTypes {
+		if relationType.ReferenceName == nil {
+			continue
+		}
+
+		linkType := LinkType{
+			ReferenceName: *relationType.ReferenceName,
+			DisplayName:   *relationType.ReferenceName,
+		}
+		if relationType.Name != nil {
+			linkType.DisplayName = *relationType.Name
+		}
+		linkType.Name = strings.ToLower(strings.Join(strings.Fields(linkType.DisplayName), "-"))
+
+		if relationType.Attributes != nil {
+			if enabled, ok := (*relationType.Attributes)["enabled"].(bool); ok && !enabled {
+				continue
+			}
+			usage, _ := (*relationType.Attributes)["usage"].(string)
+			linkType.WorkItemLink = usage == "workItemLink"
+		}
+
+		linkTypes = append(linkTypes, linkType)
+	}
+
+	slices.SortFunc(linkTypes, func(a, b LinkType) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return linkTypes, nil
+}
+
+// FindLinkType looks up link type by friendly, display or reference name.
+func FindLinkType(linkTypes []LinkType, name string) (LinkType, error) {
+	for _, linkType := range linkTypes {
+		if strings.EqualFold(linkType.Name, name) ||
+			strings.EqualFold(linkType.DisplayName, name) ||
+			strings.EqualFold(linkType.ReferenceName, name) {
+			return linkType, nil
+		}
+	}
+	return LinkType{}, fmt.Errorf("unknown link type '%s'", name)
+}
+
+// AddLinks links the work item with target work items.
+func (api *Client) AddLinks(ctx context.Context, workItemID int, linkType string, targetIDs []int, comment string) error {
+	targets, err := api.GetList(ctx, targetIDs)
+	if err != nil {
+		return err
+	}
+
+	var document []webapi.JsonPatchOperation
+	for _, target := range targets {
+		relation := workitemtracking.WorkItemRelation{
+			Rel: ptr.FromStr(linkType),
+			Url: target.Url,
+		}
+		if comment != "" {
+			relation.Attributes = &map[string]any{
+				"comment": comment,
+			}
+		}
+
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  ptr.FromStr("/relations/-"),
+			Value: relation,
+		})
+	}
+
+	return api.updateRelations(ctx, workItemID, document)
+}
+
+// RemoveLinks removes links of specified type to target work items, links are matched by URL.
+func (api *Client) RemoveLinks(ctx context.Context, workItemID int, linkType string, targetIDs []int) error {
+	w, err := api.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{
+		Id:     &workItemID,
+		Expand: &workitemtracking.WorkItemExpandValues.Relations,
+	})
+	if err != nil {
+		return err
+	}
+
+	indexes := RelationIndexes(w, func(relation workitemtracking.WorkItemRelation) bool {
+		return *relation.Rel == linkType && slices.Contains(targetIDs, GetLinkedWorkItemID(*relation.Url))
+	})
+	if len(indexes) == 0 {
+		return fmt.Errorf("no '%s' links to %v found", linkType, targetIDs)
+	}
+
+	document := removeRelationsPatch(w, indexes)
+	return api.updateRelations(ctx, workItemID, document)
+}
+
+// SetParent replaces the parent of the work item.
+// The work item should be fetched with relations, so existing parent links are found by type, not guessed by index.
+func (api *Client) SetParent(ctx context.Context, w *workitemtracking.WorkItem, parentURL string) error {
+	indexes := RelationIndexes(w, func(relation workitemtracking.WorkItemRelation) bool {
+		return *relation.Rel == ParentLinkType
+	})
+
+	document := removeRelationsPatch(w, indexes)
+	document = append(document, webapi.JsonPatchOperation{
+		Op:   &webapi.OperationValues.Add,
+		Path: ptr.FromStr("/relations/-"),
+		Value: workitemtracking.WorkItemRelation{
+			Rel: ptr.FromStr(ParentLinkType),
+			Url: &parentURL,
+		},
+	})
+
+	return api.updateRelations(ctx, *w.Id, document)
+}
+
+func (api *Client) updateRelations(ctx context.Context, workItemID int, document []webapi.JsonPatchOperation) error {
+	_, err := api.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id:       &workItemID,
+		Project:  &api.project,
+		Document: &document,
+	})
+	return err
+}
+
+// RelationIndexes returns indexes of the work item relations matched by predicate.
+func RelationIndexes(w *workitemtracking.WorkItem, predicate func(workitemtracking.WorkItemRelation) bool) []int {
+	if w.Relations == nil {
+		return nil
+	}
+
+	var indexes []int
+	for i, relation := range *w.Relations {
+		if relation.Rel != nil && relation.Url != nil && predicate(relation) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// removeRelationsPatch removes relations from the end, so indexes of remaining ones are not shifted.
+// The revision is tested to be sure relations were not changed since the work item was fetched.
+func removeRelationsPatch(w *workitemtracking.WorkItem, indexes []int) []webapi.JsonPatchOperation {
+	var document []webapi.JsonPatchOperation
+	if len(indexes) == 0 {
+		return document
+	}
+
+	if w.Rev != nil {
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Test,
+			Path:  ptr.FromStr("/rev"),
+			Value: *w.Rev,
+		})
+	}
+
+	indexes = slices.Clone(indexes)
+	slices.Sort(indexes)
+	slices.Reverse(indexes)
+	for _, index := range indexes {
+		document = append(document, webapi.JsonPatchOperation{
+			Op:   &webapi.OperationValues.Remove,
+			Path: ptr.FromStr(fmt.Sprintf("/relations/%d", index)),
+		})
+	}
+
+	return document
+}
+
+// GetLinks returns work item relations described with link types, attachments are skipped.
+func GetLinks(w *workitemtracking.WorkItem, linkTypes []LinkType) []Link {
+	if w.Relations == nil {
+		return nil
+	}
+
+	var links []Link
+	for _, relation := range *w.Relations {
+		if relation.Rel == nil || relation.Url == nil || *relation.Rel == AttachedFileLinkType {
+			continue
+		}
+
+		linkType, err := FindLinkType(linkTypes, *relation.Rel)
+		if err != nil {
+			linkType = LinkType{Name: *relation.Rel, DisplayName: *relation.Rel, ReferenceName: *relation.Rel}
+		}
+
+		link := Link{
+			Type:       linkType,
+			URL:        *relation.Url,
+			WorkItemID: GetLinkedWorkItemID(*relation.Url),
+		}
+
+		if relation.Attributes != nil {
+			link.Comment, _ = (*relation.Attributes)["comment"].(string)
+			if *relation.Rel == ArtifactLinkType {
+				name, _ := (*relation.Attributes)["name"].(string)
+				link.Artifact = describeArtifact(name, *relation.Url)
+			}
+		}
+
+		links = append(links, link)
+	}
+
+	return links
+}
+
+// GetLinkedWorkItemID returns ID of work item from relation URL or 0 if URL is not a work item one.
+func GetLinkedWorkItemID(relationURL string) int {
+	match := workItemURLRegexp.FindStringSubmatch(relationURL)
+	if match == nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(match[1])
+	return id
+}
+
+// describeArtifact makes git artifact URL like vstfs:///Git/Commit/{project}%2F{repository}%2F{commit} readable.
+func describeArtifact(name, artifactURL string) string {
+	match := artifactURLRegexp.FindStringSubmatch(artifactURL)
+	if match == nil {
+		return name
+	}
+
+	id, err := url.PathUnescape(match[2])
+	if err != nil {
+		return name
+	}
+	// project and repository precede the artifact ID which may contain slashes itself
+	parts := strings.SplitN(id, "/", 3)
+	id = parts[len(parts)-1]
+
+	switch strings.ToLower(match[1]) {
+	case "commit":
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		return "commit " + id
+	case "pullrequestid":
+		return "pull request !" + id
+	default:
+		return "branch " + strings.TrimPrefix(id, "GB")
+	}
+}
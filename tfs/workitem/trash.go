@@ -0,0 +1,82 @@
+package workitem
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"tasker/ptr"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+type DeletedWorkItem struct {
+	ID        int
+	Title     string
+	Type      string
+	DeletedBy string
+	DeletedAt time.Time
+}
+
+// GetDeleted returns work items in the recycle bin of the project.
+func (api *Client) GetDeleted(ctx context.Context) ([]DeletedWorkItem, error) {
+	refs, err := api.GetDeletedWorkItemShallowReferences(ctx, workitemtracking.GetDeletedWorkItemShallowReferencesArgs{
+		Project: &api.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, ref := range *refs {
+		if ref.Id != nil {
+			ids = append(ids, *ref.Id)
+		}
+	}
+
+	var deleted []DeletedWorkItem
+	for chunk := range slices.Chunk(ids, maxBatchSize) {
+		items, err := api.GetDeletedWorkItems(ctx, workitemtracking.GetDeletedWorkItemsArgs{
+			Ids:     &chunk,
+			Project: &api.project,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range *items {
+			if item.Id == nil {
+				continue
+			}
+
+			d := DeletedWorkItem{
+				ID:        *item.Id,
+				Title:     ptr.ToStr(item.Name),
+				Type:      ptr.ToStr(item.Type),
+				DeletedBy: ptr.ToStr(item.DeletedBy),
+			}
+			if item.DeletedDate != nil {
+				d.DeletedAt, _ = time.Parse(time.RFC3339, *item.DeletedDate)
+			}
+			deleted = append(deleted, d)
+		}
+	}
+
+	slices.SortFunc(deleted, func(a, b DeletedWorkItem) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+
+	return deleted, nil
+}
+
+// Restore restores the work item from the recycle bin.
+func (api *Client) Restore(ctx context.Context, workItemID int) error {
+	_, err := api.RestoreWorkItem(ctx, workitemtracking.RestoreWorkItemArgs{
+		Payload: &workitemtracking.WorkItemDeleteUpdate{
+			IsDeleted: ptr.FromBool(false),
+		},
+		Id:      &workItemID,
+		Project: &api.project,
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package workitem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"tasker/ptr"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+type TreeNode struct {
+	WorkItem *workitemtracking.WorkItem
+	Children []*TreeNode
+}
+
+// Walk calls fn for the node and all its descendants, parents are visited before children.
+func (n *TreeNode) Walk(fn func(node *TreeNode, depth int) error) error {
+	return n.walk(fn, 0)
+}
+
+func (n *TreeNode) walk(fn func(node *TreeNode, depth int) error, depth int) error {
+	err := fn(n, depth)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range n.Children {
+		err = child.walk(fn, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns number of work items in the tree.
+func (n *TreeNode) Count() int {
+	count := 0
+	_ = n.Walk(func(_ *TreeNode, _ int) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+// GetTree returns the work item with all its descendants, work items are fetched with relations.
+func (api *Client) GetTree(ctx context.Context, rootID int) (*TreeNode, error) {
+	result, err := api.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+		Wiql: &workitemtracking.Wiql{
+			Query: ptr.FromStr(`
+				SELECT [System.Id]
+				FROM WorkItemLinks
+				WHERE
+					([Source].[System.Id] = ` + strconv.Itoa(rootID) + `)
+					AND ([System.Links.LinkType] = '` + ChildLinkType + `')
+				MODE (Recursive)
+			`),
+		},
+		Project: &api.project,
+		Team:    &api.team,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{rootID}
+	children := make(map[int][]int)
+	if result.WorkItemRelations != nil {
+		for _, link := range *result.WorkItemRelations {
+			if link.Source == nil || link.Target == nil {
+				continue
+			}
+			children[*link.Source.Id] = append(children[*link.Source.Id], *link.Target.Id)
+			ids = append(ids, *link.Target.Id)
+		}
+	}
+
+	workItems, err := api.GetListWithRelations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*TreeNode, len(workItems))
+	for i := range workItems {
+		nodes[*workItems[i].Id] = &TreeNode{WorkItem: &workItems[i]}
+	}
+
+	root, ok := nodes[rootID]
+	if !ok {
+		return nil, fmt.Errorf("work item %d not found", rootID)
+	}
+
+	for parentID, childIDs := range children {
+		parent, ok := nodes[parentID]
+		if !ok {
+			continue
+		}
+		for _, childID := range childIDs {
+			if child, ok := nodes[childID]; ok {
+				parent.Children = append(parent.Children, child)
+			}
+		}
+	}
+
+	return root, nil
+}
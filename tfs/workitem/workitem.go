@@ -4,16 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"tasker/ptr"
+	"time"
 
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/webapi"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
 	"github.com/spf13/viper"
-	"golang.org/x/exp/slices"
 )
 
+const maxBatchSize = 200
+
 type Client struct {
 	workitemtracking.Client
 	project string
@@ -21,8 +24,9 @@ type Client struct {
 }
 
 type Relation struct {
-	URL  string
-	Type string
+	URL        string
+	Type       string
+	Attributes map[string]any
 }
 
 type Field struct {
@@ -65,6 +69,25 @@ func (api *Client) Update(ctx context.Context, taskID int, title, description st
 	return err
 }
 
+func (api *Client) UpdateFields(ctx context.Context, workItemID int, fields []*Field) error {
+	document := make([]webapi.JsonPatchOperation, 0, len(fields))
+	for _, field := range fields {
+		document = append(document, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  field.Path,
+			Value: field.Value,
+		})
+	}
+
+	_, err := api.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+		Id:       ptr.FromInt(workItemID),
+		Project:  &api.project,
+		Document: &document,
+	})
+
+	return err
+}
+
 func (api *Client) Get(ctx context.Context, workItemID int) (*workitemtracking.WorkItem, error) {
 	return api.GetWorkItem(ctx, workitemtracking.GetWorkItemArgs{
 		Id: ptr.FromInt(workItemID),
@@ -85,10 +108,96 @@ func (api *Client) GetListExpanded(ctx context.Context, workItemIDs []int) (*[]w
 	})
 }
 
-func (api *Client) Delete(ctx context.Context, workItemID int) error {
+func (api *Client) GetList(ctx context.Context, workItemIDs []int) ([]workitemtracking.WorkItem, error) {
+	return api.getList(ctx, workItemIDs, nil)
+}
+
+func (api *Client) GetListWithRelations(ctx context.Context, workItemIDs []int) ([]workitemtracking.WorkItem, error) {
+	return api.getList(ctx, workItemIDs, &workitemtracking.WorkItemExpandValues.Relations)
+}
+
+func (api *Client) getList(ctx context.Context, workItemIDs []int, expand *workitemtracking.WorkItemExpand) ([]workitemtracking.WorkItem, error) {
+	var workItems []workitemtracking.WorkItem
+	for _, chunk := range slices.Collect(slices.Chunk(workItemIDs, maxBatchSize)) {
+		result, err := api.GetWorkItems(ctx, workitemtracking.GetWorkItemsArgs{
+			Ids:    &chunk,
+			Expand: expand,
+		})
+		if err != nil {
+			return nil, err
+		}
+		workItems = append(workItems, *result...)
+	}
+
+	return workItems, nil
+}
+
+func (api *Client) GetAllRevisions(ctx context.Context, workItemID int) ([]workitemtracking.WorkItem, error) {
+	var revisions []workitemtracking.WorkItem
+	for {
+		result, err := api.GetRevisions(ctx, workitemtracking.GetRevisionsArgs{
+			Id:      ptr.FromInt(workItemID),
+			Project: &api.project,
+			Skip:    ptr.FromInt(len(revisions)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, *result...)
+		if len(*result) == 0 || len(*result) < maxBatchSize {
+			return revisions, nil
+		}
+	}
+}
+
+func (api *Client) GetAllUpdates(ctx context.Context, workItemID int) ([]workitemtracking.WorkItemUpdate, error) {
+	var updates []workitemtracking.WorkItemUpdate
+	for {
+		result, err := api.GetUpdates(ctx, workitemtracking.GetUpdatesArgs{
+			Id:      ptr.FromInt(workItemID),
+			Project: &api.project,
+			Top:     ptr.FromInt(maxBatchSize),
+			Skip:    ptr.FromInt(len(updates)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, *result...)
+		if len(*result) < maxBatchSize {
+			return updates, nil
+		}
+	}
+}
+
+func (api *Client) QueryIDs(ctx context.Context, wiql string) ([]int, error) {
+	result, err := api.QueryByWiql(ctx, workitemtracking.QueryByWiqlArgs{
+		Wiql: &workitemtracking.Wiql{
+			Query: ptr.FromStr(wiql),
+		},
+		Project: &api.project,
+		Team:    &api.team,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if result.WorkItems != nil {
+		for _, wi := range *result.WorkItems {
+			ids = append(ids, *wi.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+// Delete moves the work item to the recycle bin, or destroys it permanently if destroy is true.
+func (api *Client) Delete(ctx context.Context, workItemID int, destroy bool) error {
 	_, err := api.DeleteWorkItem(ctx, workitemtracking.DeleteWorkItemArgs{
 		Project: &api.project,
-		Destroy: ptr.FromBool(true),
+		Destroy: &destroy,
 		Id:      ptr.FromInt(workItemID),
 	})
 
@@ -167,7 +276,7 @@ func (api *Client) FindRequirement(ctx context.Context, namePattern, iterationPa
 	return nil, nil
 }
 
-func (api *Client) CreateRequirement(ctx context.Context, requirementType, title, description, areaPath, iterationPath string, estimate, priority float32, relations []*Relation, tags []string) (*workitemtracking.WorkItem, error) {
+func (api *Client) CreateRequirement(ctx context.Context, requirementType, title, description, areaPath, iterationPath string, estimate, priority float32, relations []*Relation, tags []string, extraFields ...*Field) (*workitemtracking.WorkItem, error) {
 	fields := []*Field{
 		{
 			Path:  ptr.FromStr("/fields/Microsoft.VSTS.CMMI.RequirementType"),
@@ -187,10 +296,10 @@ func (api *Client) CreateRequirement(ctx context.Context, requirementType, title
 		},
 	}
 
-	return api.create(ctx, "Requirement", title, description, areaPath, iterationPath, estimate, relations, fields, tags)
+	return api.create(ctx, "Requirement", title, description, areaPath, iterationPath, estimate, relations, mergeFields(fields, extraFields), tags)
 }
 
-func (api *Client) CreateTask(ctx context.Context, title, description, areaPath, iterationPath string, estimate float32, relations []*Relation, tags []string) (*workitemtracking.WorkItem, error) {
+func (api *Client) CreateTask(ctx context.Context, title, description, areaPath, iterationPath string, estimate float32, relations []*Relation, tags []string, extraFields ...*Field) (*workitemtracking.WorkItem, error) {
 	discipline := viper.GetString("tfsDiscipline")
 	fields := []*Field{
 		{
@@ -207,7 +316,22 @@ func (api *Client) CreateTask(ctx context.Context, title, description, areaPath,
 		},
 	}
 
-	return api.create(ctx, "Task", title, description, areaPath, iterationPath, estimate, relations, fields, tags)
+	return api.create(ctx, "Task", title, description, areaPath, iterationPath, estimate, relations, mergeFields(fields, extraFields), tags)
+}
+
+// mergeFields replaces fields by extra ones with the same path and appends the rest of extra fields.
+func mergeFields(fields, extraFields []*Field) []*Field {
+	for _, extra := range extraFields {
+		index := slices.IndexFunc(fields, func(f *Field) bool {
+			return *f.Path == *extra.Path
+		})
+		if index >= 0 {
+			fields[index] = extra
+		} else {
+			fields = append(fields, extra)
+		}
+	}
+	return fields
 }
 
 func (api *Client) create(ctx context.Context, workitemType, title, description, areaPath, iterationPath string, estimate float32, relations []*Relation, fields []*Field, tags []string) (*workitemtracking.WorkItem, error) {
@@ -257,12 +381,9 @@ func (api *Client) create(ctx context.Context, workitemType, title, description,
 
 	for _, relation := range relations {
 		documentFields = append(documentFields, webapi.JsonPatchOperation{
-			Op:   &webapi.OperationValues.Add,
-			Path: ptr.FromStr("/relations/-"),
-			Value: workitemtracking.WorkItemRelation{
-				Rel: ptr.FromStr(relation.Type),
-				Url: &relation.URL,
-			},
+			Op:    &webapi.OperationValues.Add,
+			Path:  ptr.FromStr("/relations/-"),
+			Value: newRelation(relation),
 		})
 	}
 
@@ -279,6 +400,22 @@ func (api *Client) create(ctx context.Context, workitemType, title, description,
 }
 
 func (api *Client) Copy(ctx context.Context, sourceWorkItem *workitemtracking.WorkItem, areaPath, iterationPath string, relations []*Relation, tags []string) (*workitemtracking.WorkItem, error) {
+	return api.CopyWithFields(ctx, sourceWorkItem, areaPath, iterationPath, relations, tags, nil)
+}
+
+// copiedNumericFields are numeric fields copied along with string ones, other numeric fields are system ones like IDs.
+var copiedNumericFields = []string{
+	"Microsoft.VSTS.Scheduling.OriginalEstimate",
+	"Microsoft.VSTS.Scheduling.StoryPoints",
+	"Microsoft.VSTS.Scheduling.Effort",
+	"Microsoft.VSTS.Scheduling.Size",
+	"Microsoft.VSTS.Common.Priority",
+	"Microsoft.VSTS.Common.BusinessValue",
+	"Microsoft.VSTS.Common.TimeCriticality",
+}
+
+// CopyWithFields copies work item string fields, the specified fields override copied ones.
+func (api *Client) CopyWithFields(ctx context.Context, sourceWorkItem *workitemtracking.WorkItem, areaPath, iterationPath string, relations []*Relation, tags []string, overrides []*Field) (*workitemtracking.WorkItem, error) {
 	fields := []webapi.JsonPatchOperation{
 		{
 			Op:    &webapi.OperationValues.Add,
@@ -297,36 +434,36 @@ func (api *Client) Copy(ctx context.Context, sourceWorkItem *workitemtracking.Wo
 		},
 	}
 
+	for _, field := range overrides {
+		fields = append(fields, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  field.Path,
+			Value: field.Value,
+		})
+	}
+
 	for key, fieldValue := range *sourceWorkItem.Fields {
 		if strings.Contains(key, "BoardColumn") {
 			continue
 		}
 
-		if value, ok := fieldValue.(string); ok {
-			path := "/fields/" + key
+		if _, ok := fieldValue.(string); !ok && !slices.Contains(copiedNumericFields, key) {
+			continue
+		}
 
-			if slices.ContainsFunc(fields, func(f webapi.JsonPatchOperation) bool {
-				return *f.Path == path
-			}) {
-				continue
-			}
+		fields = appendFieldIfMissing(fields, "/fields/"+key, fieldValue)
 
-			fields = append(fields, webapi.JsonPatchOperation{
-				Op:    &webapi.OperationValues.Add,
-				Path:  &path,
-				Value: value,
-			})
+		// the copy is not started yet, so all the estimate is remaining
+		if key == "Microsoft.VSTS.Scheduling.OriginalEstimate" {
+			fields = appendFieldIfMissing(fields, "/fields/Microsoft.VSTS.Scheduling.RemainingWork", fieldValue)
 		}
 	}
 
 	for _, relation := range relations {
 		fields = append(fields, webapi.JsonPatchOperation{
-			Op:   &webapi.OperationValues.Add,
-			Path: ptr.FromStr("/relations/-"),
-			Value: workitemtracking.WorkItemRelation{
-				Rel: ptr.FromStr(relation.Type),
-				Url: &relation.URL,
-			},
+			Op:    &webapi.OperationValues.Add,
+			Path:  ptr.FromStr("/relations/-"),
+			Value: newRelation(relation),
 		})
 	}
 
@@ -343,6 +480,31 @@ func (api *Client) Copy(ctx context.Context, sourceWorkItem *workitemtracking.Wo
 	return task, nil
 }
 
+func appendFieldIfMissing(fields []webapi.JsonPatchOperation, path string, value any) []webapi.JsonPatchOperation {
+	if slices.ContainsFunc(fields, func(f webapi.JsonPatchOperation) bool {
+		return *f.Path == path
+	}) {
+		return fields
+	}
+
+	return append(fields, webapi.JsonPatchOperation{
+		Op:    &webapi.OperationValues.Add,
+		Path:  &path,
+		Value: value,
+	})
+}
+
+func newRelation(relation *Relation) workitemtracking.WorkItemRelation {
+	result := workitemtracking.WorkItemRelation{
+		Rel: ptr.FromStr(relation.Type),
+		Url: &relation.URL,
+	}
+	if len(relation.Attributes) > 0 {
+		result.Attributes = &relation.Attributes
+	}
+	return result
+}
+
 func (api *Client) Assign(ctx context.Context, task *workitemtracking.WorkItem, user string) error {
 	_, err := api.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
 		Id:      task.Id,
@@ -369,6 +531,18 @@ func (api *Client) Assign(ctx context.Context, task *workitemtracking.WorkItem,
 	return err
 }
 
+func GetParentURL(w *workitemtracking.WorkItem) string {
+	if w.Relations == nil {
+		return ""
+	}
+	for _, relation := range *w.Relations {
+		if relation.Rel != nil && *relation.Rel == ParentLinkType && relation.Url != nil {
+			return *relation.Url
+		}
+	}
+	return ""
+}
+
 func GetURL(w *workitemtracking.WorkItem) string {
 	lm, ok := w.Links.(map[string]any)
 	if ok {
@@ -444,3 +618,81 @@ func GetTags(w *workitemtracking.WorkItem) []string {
 	}
 	return nil
 }
+
+func GetDescription(w *workitemtracking.WorkItem) string {
+	return getString(w, "System.Description")
+}
+
+func GetState(w *workitemtracking.WorkItem) string {
+	return getString(w, "System.State")
+}
+
+func GetDiscipline(w *workitemtracking.WorkItem) string {
+	return getString(w, "Microsoft.VSTS.Common.Discipline")
+}
+
+func GetAssignedTo(w *workitemtracking.WorkItem) string {
+	assignedTo, ok := (*w.Fields)["System.AssignedTo"]
+	if ok {
+		switch value := assignedTo.(type) {
+		case string:
+			return value
+		case map[string]any:
+			if displayName, ok := value["displayName"].(string); ok {
+				return displayName
+			}
+		}
+	}
+	return ""
+}
+
+func GetOriginalEstimate(w *workitemtracking.WorkItem) float32 {
+	return getFloat(w, "Microsoft.VSTS.Scheduling.OriginalEstimate")
+}
+
+func GetRemainingWork(w *workitemtracking.WorkItem) float32 {
+	return getFloat(w, "Microsoft.VSTS.Scheduling.RemainingWork")
+}
+
+func GetCompletedWork(w *workitemtracking.WorkItem) float32 {
+	return getFloat(w, "Microsoft.VSTS.Scheduling.CompletedWork")
+}
+
+func GetChangedDate(w *workitemtracking.WorkItem) time.Time {
+	changedDate, err := time.Parse(time.RFC3339, getString(w, "System.ChangedDate"))
+	if err != nil {
+		return time.Time{}
+	}
+	return changedDate
+}
+
+func IsCompleted(w *workitemtracking.WorkItem) bool {
+	state := GetState(w)
+	return state == "Closed" || state == "Resolved" || state == "Removed"
+}
+
+func getString(w *workitemtracking.WorkItem, field string) string {
+	value, ok := (*w.Fields)[field]
+	if ok {
+		str, ok := value.(string)
+		if ok {
+			return str
+		}
+	}
+	return ""
+}
+
+func getFloat(w *workitemtracking.WorkItem, field string) float32 {
+	value, ok := (*w.Fields)[field]
+	if ok {
+		switch v := value.(type) {
+		case float64:
+			return float32(v)
+		case float32:
+			return v
+		case int:
+			return float32(v)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package wiki
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DownloadAttachment writes content of the file attached to the page.
+func (a *API) DownloadAttachment(pageID, fileName string, w io.Writer) error {
+	baseAddress := strings.TrimRight(viper.GetString("wikiBaseAddress"), "/")
+	ep, err := url.ParseRequestURI(baseAddress + "/download/attachments/" + url.PathEscape(pageID) + "/" + url.PathEscape(fileName))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", ep.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.Request(req)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, bytes.NewReader(res))
+	return err
+}
+
+// GetUserName returns display name of the user mentioned in storage format content.
+func (a *API) GetUserName(user StorageUser) (string, error) {
+	u, err := a.User(user.Query())
+	if err != nil {
+		return "", err
+	}
+	if u.DisplayName != "" {
+		return u.DisplayName, nil
+	}
+	return u.Username, nil
+}
+
+// GetAttachmentIDs returns IDs of files attached to the page by file names.
+func (a *API) GetAttachmentIDs(pageID string) (map[string]string, error) {
+	ids := make(map[string]string)
+
+	for start := 0; ; {
+		endpoint, err := getContentEndpoint(pageID)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Path += "/child/attachment"
+		endpoint.RawQuery = url.Values{
+			"start": {strconv.Itoa(start)},
+			"limit": {"100"},
+		}.Encode()
+
+		result, err := a.SendSearchRequest(endpoint, "GET")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attachment := range result.Results {
+			ids[attachment.Title] = attachment.ID
+		}
+
+		// the server may reduce the limit, so the page is the last one when it's not full
+		if len(result.Results) == 0 || result.Size < result.Limit {
+			return ids, nil
+		}
+		start += len(result.Results)
+	}
+}
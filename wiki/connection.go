@@ -88,6 +88,14 @@ func getContentCopyEndpoint(pageID string) (*url.URL, error) {
 	return url.ParseRequestURI(endpoint + "/content/" + pageID + "/copy")
 }
 
+func getContentEndpoint(pageID string) (*url.URL, error) {
+	endpoint, err := getAPIBaseAddress()
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseRequestURI(endpoint + "/content/" + pageID)
+}
+
 func joinURL(base, relPath string) (string, error) {
 	u, err := url.Parse(base)
 	if err != nil {
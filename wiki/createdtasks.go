@@ -0,0 +1,54 @@
+package wiki
+
+import "slices"
+
+// CreatedTasks are IDs of TFS tasks created for rows of tasks tables by row titles. The IDs are kept until macros
+// of the tasks are found on the page, so the rows are not created again if the page was saved concurrently.
+type CreatedTasks map[string][]int
+
+// Add remembers the TFS task created for the row.
+func (c CreatedTasks) Add(t *Task, id int) {
+	title := getTaskDiffTitle(t)
+	c[title] = append(c[title], id)
+}
+
+// Apply sets IDs of created TFS tasks to rows without TFS task matched by title and returns the rows,
+// so macros of the tasks can be inserted again. Tasks whose macros are on the page are forgotten.
+func (c CreatedTasks) Apply(tasks []*Task) []*Task {
+	for _, t := range tasks {
+		if t.TfsTaskID > 0 {
+			c.forget(t.TfsTaskID)
+		}
+	}
+
+	var applied []*Task
+	// rows with the same title take created tasks in order
+	used := make(map[string]int)
+	for _, t := range tasks {
+		if t.TfsTaskID != 0 {
+			continue
+		}
+
+		title := getTaskDiffTitle(t)
+		ids := c[title]
+		if used[title] >= len(ids) {
+			continue
+		}
+		t.TfsTaskID = ids[used[title]]
+		used[title]++
+		applied = append(applied, t)
+	}
+
+	return applied
+}
+
+func (c CreatedTasks) forget(id int) {
+	for title, ids := range c {
+		ids = slices.DeleteFunc(ids, func(v int) bool { return v == id })
+		if len(ids) == 0 {
+			delete(c, title)
+		} else {
+			c[title] = ids
+		}
+	}
+}
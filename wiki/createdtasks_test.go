@@ -0,0 +1,64 @@
+package wiki
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreatedTasks(t *testing.T) {
+	macro := func(t *Task) string {
+		if t.TfsTaskID == 0 {
+			return ""
+		}
+		return `<ac:structured-macro ac:name="work-item-tfs"><ac:parameter ac:name="itemID">` + strconv.Itoa(t.TfsTaskID) + `</ac:parameter></ac:structured-macro>`
+	}
+	body := RenderTasksTable([]*Task{
+		{Title: "Контроллер", Estimate: 2},
+		{Title: "Демо", Estimate: 1},
+		{Title: "Демо", Estimate: 1},
+		{Title: "Сервис", Estimate: 3, TfsTaskID: 71711},
+		{Title: "Сведение", Estimate: 1},
+	}, macro)
+
+	tasks, err := ParseTasksTable(body)
+	require.NoError(t, err)
+	require.Len(t, tasks, 5)
+
+	// tasks are created, but the page is not saved because it was edited concurrently
+	created := make(CreatedTasks)
+	created.Add(tasks[0], 101)
+	created.Add(tasks[1], 102)
+	created.Add(tasks[2], 103)
+
+	tasks, err = ParseTasksTable(body)
+	require.NoError(t, err)
+
+	restored := created.Apply(tasks)
+	assert.Equal(t, []*Task{tasks[0], tasks[1], tasks[2]}, restored)
+	assert.Equal(t, []int{101, 102, 103, 71711, 0}, taskIDs(tasks))
+
+	for _, task := range restored {
+		require.NoError(t, task.Update(macro(task)))
+	}
+	updatedBody, modified, err := UpdatePageContent(body, tasks)
+	require.NoError(t, err)
+	assert.True(t, modified)
+
+	// tasks saved to the page are forgotten
+	tasks, err = ParseTasksTable(updatedBody)
+	require.NoError(t, err)
+	assert.Empty(t, created.Apply(tasks))
+	assert.Empty(t, created)
+	assert.Equal(t, []int{101, 102, 103, 71711, 0}, taskIDs(tasks))
+}
+
+func taskIDs(tasks []*Task) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.TfsTaskID)
+	}
+	return ids
+}
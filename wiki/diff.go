@@ -0,0 +1,120 @@
+package wiki
+
+import (
+	"slices"
+	"strings"
+
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+type TaskDiffKind int
+
+const (
+	TaskAdded TaskDiffKind = iota
+	TaskRemoved
+	TaskChanged
+)
+
+// TaskDiff is a row of the tasks table added, removed or changed between page versions,
+// Fields lists changed columns: description, estimate, tfs and tags.
+type TaskDiff struct {
+	Kind   TaskDiffKind
+	Old    *Task
+	New    *Task
+	Fields []string
+}
+
+// GetPageVersion returns the page of the version with body, the current version is returned if version is 0.
+func (a *API) GetPageVersion(pageID string, version int) (*goconfluence.Content, error) {
+	query := goconfluence.ContentQuery{
+		Expand: []string{
+			"body.storage",
+			"space",
+			"version",
+		},
+	}
+	if version > 0 {
+		// older versions are found only among historical content
+		query.Status = "historical"
+		query.Version = version
+	}
+
+	return a.GetContentByID(pageID, query)
+}
+
+// GetLastVersionWithMessage returns the latest version of the page saved with the message, 0 if there is no such version.
+func (a *API) GetLastVersionWithMessage(pageID, message string) (int, error) {
+	versions, err := a.GetContentVersion(pageID)
+	if err != nil {
+		return 0, err
+	}
+	return findLastVersion(versions.Result, message), nil
+}
+
+func findLastVersion(versions []goconfluence.Version, message string) int {
+	last := 0
+	for _, v := range versions {
+		if v.Message == message && v.Number > last {
+			last = v.Number
+		}
+	}
+	return last
+}
+
+// DiffTasks matches tasks of page versions by title and returns added, removed and changed ones,
+// tasks with the same title are matched in order of rows. Unchanged tasks are omitted.
+func DiffTasks(oldTasks, newTasks []*Task) []TaskDiff {
+	byTitle := make(map[string][]*Task)
+	for _, t := range oldTasks {
+		title := getTaskDiffTitle(t)
+		byTitle[title] = append(byTitle[title], t)
+	}
+
+	var diff []TaskDiff
+	matched := make(map[*Task]bool)
+	for _, t := range newTasks {
+		title := getTaskDiffTitle(t)
+		candidates := byTitle[title]
+		if len(candidates) == 0 {
+			diff = append(diff, TaskDiff{Kind: TaskAdded, New: t})
+			continue
+		}
+
+		old := candidates[0]
+		byTitle[title] = candidates[1:]
+		matched[old] = true
+
+		if fields := getChangedTaskFields(old, t); len(fields) > 0 {
+			diff = append(diff, TaskDiff{Kind: TaskChanged, Old: old, New: t, Fields: fields})
+		}
+	}
+
+	for _, t := range oldTasks {
+		if !matched[t] {
+			diff = append(diff, TaskDiff{Kind: TaskRemoved, Old: t})
+		}
+	}
+
+	return diff
+}
+
+func getTaskDiffTitle(t *Task) string {
+	return strings.ToLower(strings.Join(strings.Fields(t.Title), " "))
+}
+
+func getChangedTaskFields(old, new *Task) []string {
+	var fields []string
+	if old.Description != new.Description {
+		fields = append(fields, "description")
+	}
+	if old.Estimate != new.Estimate {
+		fields = append(fields, "estimate")
+	}
+	if old.TfsTaskID != new.TfsTaskID {
+		fields = append(fields, "tfs")
+	}
+	if !slices.Equal(old.Tags, new.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
@@ -0,0 +1,40 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+func Test_DiffTasks(t *testing.T) {
+	kept := &Task{Title: "Kept", Estimate: 1}
+	changedOld := &Task{Title: "Changed", Description: "old", Estimate: 2, Tags: []string{"a"}}
+	changedNew := &Task{Title: " changed ", Description: "new", Estimate: 3, Tags: []string{"a"}}
+	removed := &Task{Title: "Removed", Estimate: 1}
+	added := &Task{Title: "Added", Estimate: 1}
+
+	diff := DiffTasks(
+		[]*Task{kept, changedOld, removed},
+		[]*Task{{Title: "Kept", Estimate: 1}, changedNew, added},
+	)
+
+	assert.Equal(t, []TaskDiff{
+		{Kind: TaskChanged, Old: changedOld, New: changedNew, Fields: []string{"description", "estimate"}},
+		{Kind: TaskAdded, New: added},
+		{Kind: TaskRemoved, Old: removed},
+	}, diff)
+}
+
+func Test_findLastVersion(t *testing.T) {
+	versions := []goconfluence.Version{
+		{Number: 5, Message: "edited"},
+		{Number: 4, Message: "synced"},
+		{Number: 3},
+		{Number: 2, Message: "synced"},
+		{Number: 1},
+	}
+
+	assert.Equal(t, 4, findLastVersion(versions, "synced"))
+	assert.Equal(t, 0, findLastVersion(versions, "published"))
+}
@@ -0,0 +1,606 @@
+package wiki
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"tasker/wiki/macros"
+	"tasker/wiki/xhtml"
+
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// alertTypes are GitHub alert types of Confluence admonition macros, see alertMacros for the reverse mapping.
+var alertTypes = map[string]string{
+	"info":    "NOTE",
+	"tip":     "TIP",
+	"note":    "IMPORTANT",
+	"warning": "WARNING",
+}
+
+var (
+	whitespaceRegexp = regexp.MustCompile(`\s+`)
+	blockStartRegexp = regexp.MustCompile(`^(#{1,6}|[-+>]|\d+[.)])(\s|$)`)
+)
+
+type MarkdownOptions struct {
+	pageFile       func(spaceKey, title string) (string, bool)
+	attachmentFile func(image StorageImage) (string, bool)
+	resolveUser    func(user StorageUser) string
+	macros         *macros.Templates
+}
+
+type MarkdownOption func(options *MarkdownOptions)
+
+// PageFiles sets the function returning the path of the exported page file relative to the converted page file,
+// links to other pages are absolute wiki URLs.
+func PageFiles(fn func(spaceKey, title string) (string, bool)) MarkdownOption {
+	return func(options *MarkdownOptions) { options.pageFile = fn }
+}
+
+// AttachmentFiles sets the function returning the path of the downloaded attachment relative to the converted page file,
+// other attachments are referenced by wiki download URL.
+func AttachmentFiles(fn func(image StorageImage) (string, bool)) MarkdownOption {
+	return func(options *MarkdownOptions) { options.attachmentFile = fn }
+}
+
+// MarkdownUsers sets the function returning the name of the mentioned user, user key is shown otherwise.
+func MarkdownUsers(fn func(user StorageUser) string) MarkdownOption {
+	return func(options *MarkdownOptions) { options.resolveUser = fn }
+}
+
+// MarkdownMacros sets templates recognizing work item macros, built-in templates are used by default.
+func MarkdownMacros(templates *macros.Templates) MarkdownOption {
+	return func(options *MarkdownOptions) { options.macros = templates }
+}
+
+func getMarkdownOptions(opts ...MarkdownOption) MarkdownOptions {
+	options := MarkdownOptions{macros: macros.Default()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// markdownConverter renders storage format nodes as GFM Markdown, links and images are resolved as in tfsHTMLConverter.
+type markdownConverter struct {
+	options MarkdownOptions
+	links   *tfsHTMLConverter
+}
+
+// StorageToMarkdown converts storage format content of the wiki page into GFM Markdown: tables become pipe tables,
+// code macros become fenced code blocks, info, tip, note and warning macros become GitHub alerts, tasks become task lists.
+// Links to pages and attachments point to exported files if PageFiles and AttachmentFiles are set, otherwise to the wiki.
+func StorageToMarkdown(storage string, page *goconfluence.Content, opts ...MarkdownOption) (string, error) {
+	doc, err := xhtml.Parse(storage)
+	if err != nil {
+		return "", err
+	}
+
+	options := getMarkdownOptions(opts...)
+	c := &markdownConverter{
+		options: options,
+		links: &tfsHTMLConverter{
+			options: TfsHTMLOptions{resolveUser: options.resolveUser, macros: options.macros},
+			baseURL: getPageBaseURL(page),
+			pageID:  page.ID,
+		},
+	}
+	if page.Space != nil {
+		c.links.spaceKey = page.Space.Key
+	}
+
+	result := c.blocks(doc.Children)
+	if result == "" {
+		return "", nil
+	}
+	return result + "\n", nil
+}
+
+// blocks renders nodes as blocks separated by empty lines, adjacent inline nodes are joined into paragraphs.
+func (c *markdownConverter) blocks(nodes []*xhtml.Node) string {
+	return c.joinBlocks(nodes, "\n\n")
+}
+
+// tightBlocks renders content of the list item, blocks are separated by empty lines only if it has paragraphs.
+func (c *markdownConverter) tightBlocks(nodes []*xhtml.Node) string {
+	for _, n := range nodes {
+		if n.Type == xhtml.ElementNode && n.Name == "p" {
+			return c.blocks(nodes)
+		}
+	}
+	return c.joinBlocks(nodes, "\n")
+}
+
+func (c *markdownConverter) joinBlocks(nodes []*xhtml.Node, separator string) string {
+	var blocks []string
+	var inline []*xhtml.Node
+
+	flush := func() {
+		if text := c.paragraph(inline); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline = nil
+	}
+
+	for _, n := range nodes {
+		if n.Type == xhtml.ElementNode && c.isBlock(n) {
+			flush()
+			if block := c.block(n); block != "" {
+				blocks = append(blocks, block)
+			}
+			continue
+		}
+		inline = append(inline, n)
+	}
+	flush()
+
+	return strings.Join(blocks, separator)
+}
+
+func (c *markdownConverter) isBlock(n *xhtml.Node) bool {
+	switch n.Name {
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "table", "pre", "blockquote", "hr", "div",
+		"ac:task-list", "ac:layout", "ac:layout-section", "ac:layout-cell", "ac:rich-text-body":
+		return true
+	case "ac:structured-macro", "ac:macro":
+		name, _ := n.Attr("ac:name")
+		switch {
+		case c.options.macros.IsWorkItemMacro(name), name == "status", name == "jira":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (c *markdownConverter) block(n *xhtml.Node) string {
+	switch n.Name {
+	case "p":
+		return c.paragraph(n.Children)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Name[1:])
+		text := c.inline(n.Children)
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	case "ul", "ol":
+		return c.list(n)
+	case "ac:task-list":
+		return c.taskList(n)
+	case "table":
+		return c.table(n)
+	case "pre":
+		return fence(n.Text(), "")
+	case "blockquote":
+		return quote(c.blocks(n.Children))
+	case "hr":
+		return "---"
+	case "ac:structured-macro", "ac:macro":
+		return c.macro(n)
+	}
+	return c.blocks(n.Children)
+}
+
+// paragraph renders inline nodes, lines starting like other blocks are escaped.
+func (c *markdownConverter) paragraph(nodes []*xhtml.Node) string {
+	text := c.inline(nodes)
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if blockStartRegexp.MatchString(line) {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *markdownConverter) list(n *xhtml.Node) string {
+	var items []string
+	number := 1
+	if start, ok := n.Attr("start"); ok {
+		if value, err := strconv.Atoi(start); err == nil {
+			number = value
+		}
+	}
+
+	for _, li := range n.Elements() {
+		if li.Name != "li" {
+			continue
+		}
+
+		marker := "- "
+		if n.Name == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		items = append(items, marker+indent(c.tightBlocks(li.Children), len(marker)))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (c *markdownConverter) taskList(n *xhtml.Node) string {
+	var items []string
+	for _, task := range n.Elements() {
+		if task.Name != "ac:task" {
+			continue
+		}
+
+		marker := "- [ ] "
+		var body string
+		for _, e := range task.Elements() {
+			switch e.Name {
+			case "ac:task-status":
+				if strings.TrimSpace(e.Text()) == "complete" {
+					marker = "- [x] "
+				}
+			case "ac:task-body":
+				body = c.tightBlocks(e.Children)
+			}
+		}
+		items = append(items, marker+indent(body, 2))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table renders pipe table, the first row is the header, cell blocks are joined with <br/>.
+func (c *markdownConverter) table(n *xhtml.Node) string {
+	var rows [][]string
+	columns := 0
+	for _, tr := range n.TableRows() {
+		var row []string
+		for _, cell := range tr.RowCells() {
+			text := c.blocks(cell.Children)
+			text = strings.ReplaceAll(text, "\n\n", "<br/>")
+			text = strings.ReplaceAll(text, "\n", "<br/>")
+			row = append(row, strings.ReplaceAll(text, "|", `\|`))
+		}
+		rows = append(rows, row)
+		columns = max(columns, len(row))
+	}
+
+	if len(rows) == 0 || columns == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (c *markdownConverter) macro(n *xhtml.Node) string {
+	name, _ := n.Attr("ac:name")
+
+	switch name {
+	case "code", "noformat":
+		var text string
+		for _, body := range n.Elements() {
+			if body.Name == "ac:plain-text-body" {
+				text = body.Text()
+			}
+		}
+		return fence(text, strings.TrimSpace(getMacroParameter(n, "language")))
+	case "anchor", "toc", "children", "pagetree", "recently-updated":
+		return ""
+	}
+
+	var blocks []string
+	if title := strings.TrimSpace(getMacroParameter(n, "title")); title != "" {
+		blocks = append(blocks, "**"+escapeMarkdown(title)+"**")
+	}
+	for _, body := range n.Elements() {
+		if body.Name == "ac:rich-text-body" {
+			if text := c.blocks(body.Children); text != "" {
+				blocks = append(blocks, text)
+			}
+		}
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	content := strings.Join(blocks, "\n\n")
+	if alert, ok := alertTypes[name]; ok {
+		return quote("[!" + alert + "]\n" + content)
+	}
+	return content
+}
+
+// inline renders nodes as trimmed inline text.
+func (c *markdownConverter) inline(nodes []*xhtml.Node) string {
+	return strings.TrimSpace(c.inlineText(nodes))
+}
+
+// inlineText renders nodes as inline text, whitespace is collapsed as in HTML.
+func (c *markdownConverter) inlineText(nodes []*xhtml.Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		sb.WriteString(c.inlineNode(n))
+	}
+	return strings.ReplaceAll(whitespaceRegexp.ReplaceAllString(sb.String(), " "), "<br/> ", "<br/>")
+}
+
+func (c *markdownConverter) inlineNode(n *xhtml.Node) string {
+	switch n.Type {
+	case xhtml.TextNode:
+		return escapeMarkdown(html.UnescapeString(n.Data))
+	case xhtml.CDATANode:
+		return escapeMarkdown(n.Data)
+	case xhtml.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Name {
+	case "strong", "b":
+		return emphasis("**", c.inlineText(n.Children))
+	case "em", "i":
+		return emphasis("*", c.inlineText(n.Children))
+	case "del", "s":
+		return emphasis("~~", c.inlineText(n.Children))
+	case "code":
+		return codeSpan(n.Text())
+	case "br":
+		return "<br/>"
+	case "a":
+		href, _ := n.Attr("href")
+		return link(c.inline(n.Children), c.links.absoluteURL(href))
+	case "img":
+		src, _ := n.Attr("src")
+		alt, _ := n.Attr("alt")
+		return "![" + escapeMarkdown(alt) + "](" + destination(c.links.absoluteURL(src)) + ")"
+	case "ac:link":
+		return c.link(n)
+	case "ac:image":
+		return c.image(n)
+	case "ac:emoticon":
+		if fallback, ok := n.Attr("ac:emoji-fallback"); ok && fallback != "" {
+			return fallback
+		}
+		name, _ := n.Attr("ac:name")
+		return emoticons[name]
+	case "ac:structured-macro", "ac:macro":
+		return c.inlineMacro(n)
+	case "ac:parameter", "ac:placeholder", "ac:plain-text-body", "ac:plain-text-link-body":
+		return ""
+	case "time":
+		if datetime, ok := n.Attr("datetime"); ok && len(n.Children) == 0 {
+			return datetime
+		}
+	}
+
+	if c.isBlock(n) {
+		// block inside of inline content, e.g. paragraph in the link body
+		return " " + c.inline(n.Children) + " "
+	}
+	return c.inline(n.Children)
+}
+
+func (c *markdownConverter) inlineMacro(n *xhtml.Node) string {
+	name, _ := n.Attr("ac:name")
+	switch {
+	case c.options.macros.IsWorkItemMacro(name):
+		id := strings.TrimSpace(getMacroParameter(n, "itemID"))
+		if _, err := strconv.Atoi(id); err == nil {
+			return "#" + id
+		}
+		return escapeMarkdown(id)
+	case name == "status":
+		title := getMacroParameter(n, "title")
+		if title == "" {
+			title = getMacroParameter(n, "colour")
+		}
+		return "**[" + escapeMarkdown(title) + "]**"
+	case name == "jira":
+		return escapeMarkdown(getMacroParameter(n, "key"))
+	}
+	return ""
+}
+
+func (c *markdownConverter) link(n *xhtml.Node) string {
+	var href, text string
+	for _, target := range n.Elements() {
+		switch target.Name {
+		case "ri:page", "ri:blog-post":
+			title, _ := target.Attr("ri:content-title")
+			spaceKey, _ := target.Attr("ri:space-key")
+			href, text = c.links.pageURL(spaceKey, title), title
+			if c.options.pageFile != nil && title != "" {
+				if file, ok := c.options.pageFile(spaceKey, title); ok {
+					href = file
+				}
+			}
+		case "ri:attachment":
+			text, _ = target.Attr("ri:filename")
+			href = c.attachment(target)
+		case "ri:url":
+			href, _ = target.Attr("ri:value")
+			text = href
+		case "ri:space":
+			spaceKey, _ := target.Attr("ri:space-key")
+			href, text = c.links.baseURL+"/display/"+url.PathEscape(spaceKey), spaceKey
+		case "ri:user":
+			if body := getLinkBody(n); body != nil {
+				return c.inline(body.Children)
+			}
+			return escapeMarkdown(c.links.userName(target))
+		}
+	}
+
+	if anchor, ok := n.Attr("ac:anchor"); ok && anchor != "" {
+		href += "#" + url.PathEscape(anchor)
+	}
+
+	if body := getLinkBody(n); body != nil {
+		if body.Name == "ac:plain-text-link-body" {
+			text = escapeMarkdown(body.Text())
+		} else {
+			text = c.inline(body.Children)
+		}
+	} else {
+		text = escapeMarkdown(text)
+	}
+	return link(text, href)
+}
+
+func (c *markdownConverter) image(n *xhtml.Node) string {
+	var src string
+	for _, source := range n.Elements() {
+		switch source.Name {
+		case "ri:url":
+			src, _ = source.Attr("ri:value")
+		case "ri:attachment":
+			src = c.attachment(source)
+		}
+	}
+
+	if src == "" {
+		return ""
+	}
+
+	alt, _ := n.Attr("ac:alt")
+	result := "![" + escapeMarkdown(alt) + "](" + destination(src)
+	if title, ok := n.Attr("ac:title"); ok && title != "" {
+		result += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+	}
+	return result + ")"
+}
+
+// attachment returns the path of the downloaded attachment or its wiki URL.
+func (c *markdownConverter) attachment(attachment *xhtml.Node) string {
+	if c.options.attachmentFile != nil {
+		if file, ok := c.options.attachmentFile(c.links.storageImage(attachment)); ok {
+			return file
+		}
+	}
+	return c.links.attachmentURL(attachment)
+}
+
+// fence returns fenced code block, the fence is longer than backtick runs of the code.
+func fence(code, language string) string {
+	longest := 0
+	run := 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	marker := strings.Repeat("`", max(3, longest+1))
+	return marker + language + "\n" + strings.TrimSuffix(code, "\n") + "\n" + marker
+}
+
+func codeSpan(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if strings.Contains(code, "`") {
+		return "`` " + code + " ``"
+	}
+	return "`" + code + "`"
+}
+
+// emphasis wraps the text with the marker, surrounding spaces are moved outside of emphasis.
+func emphasis(marker, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
+
+func link(text, href string) string {
+	if href == "" {
+		return text
+	}
+	if text == "" {
+		text = escapeMarkdown(href)
+	}
+	return "[" + text + "](" + destination(href) + ")"
+}
+
+// destination returns link destination, it's enclosed in <> if contains spaces or parentheses.
+func destination(href string) string {
+	if strings.ContainsAny(href, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+	}
+	return href
+}
+
+func quote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indent indents all lines but the first one, so the text continues the list item.
+func indent(text string, width int) string {
+	prefix := strings.Repeat(" ", width)
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeMarkdown escapes characters having meaning in inline Markdown,
+// underscores are escaped only at word boundaries, since they don't emphasize inside of words.
+func escapeMarkdown(text string) string {
+	var sb strings.Builder
+	for i, r := range text {
+		switch r {
+		case '\\', '`', '*', '[', ']', '<':
+			sb.WriteByte('\\')
+		case '_':
+			before, _ := utf8.DecodeLastRuneInString(text[:i])
+			after, _ := utf8.DecodeRuneInString(text[i+1:])
+			if !isWordRune(before) || !isWordRune(after) {
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,75 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+func Test_StorageToMarkdown(t *testing.T) {
+	page := &goconfluence.Content{
+		ID:    "100",
+		Space: &goconfluence.Space{Key: "SP"},
+		Links: &goconfluence.Links{Base: "https://wiki.local"},
+	}
+
+	tests := []struct {
+		name     string
+		storage  string
+		expected string
+	}{
+		{
+			name:     "headings and inline",
+			storage:  `<h2>Title</h2><p>Text <strong>bold </strong>and <em>it</em>, <code>a*b</code> snake_case _x<br/>next</p><p># not heading</p>`,
+			expected: "## Title\n\nText **bold** and *it*, `a*b` snake_case \\_x<br/>next\n\n\\# not heading\n",
+		},
+		{
+			name:     "lists",
+			storage:  `<ul><li>one<ul><li>nested</li></ul></li><li><p>two</p></li></ul><ol><li>first</li><li>second</li></ol>`,
+			expected: "- one\n  - nested\n- two\n\n1. first\n2. second\n",
+		},
+		{
+			name: "table",
+			storage: `<table><tbody><tr><th>Name</th><th>Value</th></tr>` +
+				`<tr><td><p>a|b</p><p>c</p></td><td>1</td></tr><tr><td>short</td></tr></tbody></table>`,
+			expected: "| Name | Value |\n| --- | --- |\n| a\\|b<br/>c | 1 |\n| short |  |\n",
+		},
+		{
+			name: "code macro",
+			storage: `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter>` +
+				"<ac:plain-text-body><![CDATA[fmt.Println(\"```\")\n]]></ac:plain-text-body></ac:structured-macro>",
+			expected: "````go\nfmt.Println(\"```\")\n````\n",
+		},
+		{
+			name:     "alert and tasks",
+			storage:  `<ac:structured-macro ac:name="warning"><ac:rich-text-body><p>Be careful</p></ac:rich-text-body></ac:structured-macro><ac:task-list><ac:task><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task><ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>todo</ac:task-body></ac:task></ac:task-list>`,
+			expected: "> [!WARNING]\n> Be careful\n\n- [x] done\n- [ ] todo\n",
+		},
+		{
+			name: "links and images",
+			storage: `<p><ac:link ac:anchor="install"><ri:page ri:content-title="Setup" /><ac:link-body>guide</ac:link-body></ac:link> ` +
+				`<ac:link><ri:page ri:content-title="Other page" /></ac:link> ` +
+				`<ac:image ac:alt="d"><ri:attachment ri:filename="diagram.png" /></ac:image> ` +
+				`<ac:structured-macro ac:name="work-item-tfs"><ac:parameter ac:name="itemID">42</ac:parameter></ac:structured-macro></p>`,
+			expected: "[guide](Setup.md#install) [Other page](https://wiki.local/display/SP/Other+page) ![d](index.assets/diagram.png) #42\n",
+		},
+	}
+
+	opts := []MarkdownOption{
+		PageFiles(func(spaceKey, title string) (string, bool) {
+			return title + ".md", title == "Setup"
+		}),
+		AttachmentFiles(func(image StorageImage) (string, bool) {
+			return "index.assets/" + image.FileName, image.PageID == "100"
+		}),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := StorageToMarkdown(tt.storage, page, opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
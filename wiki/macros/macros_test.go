@@ -0,0 +1,83 @@
+package macros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseReferences(t *testing.T) {
+	storage := `<p>Task <ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1">` +
+		`<ac:parameter ac:name="itemID">101</ac:parameter><ac:parameter ac:name="host">1</ac:parameter></ac:structured-macro></p>` +
+		`<p>See <a href="https://tfs.local/P/_workitems/edit/102/">task</a>&nbsp;and #103, https://tfs.local/P/_workitems/edit/104 <br/>` +
+		`<ac:link><ri:url ri:value="https://tfs.local/P/_workitems/edit/105" /></ac:link> #101 again</p>` +
+		`<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[x := a#106 // #107]]></ac:plain-text-body></ac:structured-macro>` +
+		`<ac:structured-macro ac:name="status"><ac:parameter ac:name="title">#108</ac:parameter></ac:structured-macro><code>#109</code>`
+
+	references, err := Default().ParseReferences(storage)
+	assert.NoError(t, err)
+	assert.Equal(t, []Reference{
+		{ID: 101, Kind: MacroReference},
+		{ID: 102, Kind: LinkReference},
+		{ID: 104, Kind: LinkReference},
+		{ID: 103, Kind: MentionReference},
+		{ID: 105, Kind: LinkReference},
+	}, references)
+}
+
+func Test_Templates(t *testing.T) {
+	id := 42
+	w := &workitemtracking.WorkItem{
+		Id:     &id,
+		Fields: &map[string]any{"System.Title": "Title <1>", "System.State": "Active"},
+	}
+
+	path := filepath.Join(t.TempDir(), "task.xml")
+	assert.NoError(t, os.WriteFile(path, []byte(`<p>{{.Task.Id}}</p>`), 0o600))
+
+	templates, err := New(map[string]Template{
+		"task":    {Path: path},
+		"status":  {Text: `[{{escape .Status}}]`},
+		"pr":      {},
+		"mention": {Text: `<ac:structured-macro ac:name="tfs-mention"><ac:parameter ac:name="itemID">{{.ID}}</ac:parameter></ac:structured-macro>`},
+	})
+	assert.NoError(t, err)
+
+	macro, err := templates.Render(Task, WorkItemData(w))
+	assert.NoError(t, err)
+	assert.Equal(t, `<p>42</p>`, macro)
+
+	macro, err = templates.Render(Status, WorkItemData(w))
+	assert.NoError(t, err)
+	assert.Equal(t, `[Active]`, macro)
+
+	macro, err = templates.Render(PullRequest, Data{ID: 7, Title: "Fix <a>", URL: "https://tfs.local/pr/7"})
+	assert.NoError(t, err)
+	assert.Equal(t, `<a href="https://tfs.local/pr/7">PR 7 Fix &lt;a&gt;</a>`, macro)
+
+	references, err := Default().ParseReferences(must(Default().Render(Requirement, WorkItemData(w))))
+	assert.NoError(t, err)
+	assert.Equal(t, []Reference{{ID: 42, Kind: MacroReference}}, references)
+
+	assert.True(t, templates.IsWorkItemMacro("tfs-mention"))
+	assert.True(t, templates.IsWorkItemMacro("work-item-tfs"))
+	assert.False(t, templates.IsWorkItemMacro("status"))
+	assert.False(t, Default().IsWorkItemMacro("tfs-mention"))
+
+	references, err = templates.ParseReferences(must(templates.Render(Mention, WorkItemData(w))) + ` #43`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Reference{{ID: 42, Kind: MacroReference}, {ID: 43, Kind: MentionReference}}, references)
+
+	_, err = New(map[string]Template{"unknown": {Text: "x"}})
+	assert.EqualError(t, err, `unknown macro template "unknown"`)
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
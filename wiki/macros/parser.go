@@ -0,0 +1,177 @@
+package macros
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"tasker/wiki/xhtml"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+var (
+	// WorkItemMentionRegexp matches work item mentions like #123 in text, but not in words, entities and URLs.
+	WorkItemMentionRegexp = regexp.MustCompile(`(^|[^\w&/#])#(\d+)\b`)
+	// WorkItemURLRegexp matches web URL of the work item.
+	WorkItemURLRegexp = regexp.MustCompile(`/_workitems/edit/(\d+)`)
+
+	// workItemTextURLRegexp matches work item URL written as plain text.
+	workItemTextURLRegexp = regexp.MustCompile(`https?://\S+/_workitems/edit/(\d+)`)
+)
+
+// workItemMacroName is the name of Confluence macro showing TFS work item, pages may have it whatever templates are configured.
+const workItemMacroName = "work-item-tfs"
+
+// ReferenceKind is the way the work item is referenced.
+type ReferenceKind int
+
+const (
+	// MacroReference is a work item macro.
+	MacroReference ReferenceKind = iota
+	// LinkReference is a link or plain text URL of the work item.
+	LinkReference
+	// MentionReference is a #123 mention in text.
+	MentionReference
+)
+
+// Reference is a work item referenced by storage format content.
+type Reference struct {
+	ID   int
+	Kind ReferenceKind
+}
+
+// IsWorkItemMacro reports whether the macro with the name shows TFS work item: work-item-tfs macro
+// or the macro with itemID parameter rendered by work item templates.
+func (t *Templates) IsWorkItemMacro(name string) bool {
+	return name == workItemMacroName || t.workItemMacros[name]
+}
+
+// ParseReferences returns work items referenced by storage format content in document order, each work item is returned once.
+// Work item macros, links and plain text URLs of work items and #123 mentions are recognized,
+// text of code blocks and macro parameters is skipped.
+func (t *Templates) ParseReferences(storage string) ([]Reference, error) {
+	doc, err := xhtml.Parse(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []Reference
+	seen := make(map[int]bool)
+	add := func(id int, kind ReferenceKind) {
+		if !seen[id] {
+			seen[id] = true
+			references = append(references, Reference{ID: id, Kind: kind})
+		}
+	}
+
+	var visit func(n *xhtml.Node, inCode bool)
+	visit = func(n *xhtml.Node, inCode bool) {
+		switch n.Type {
+		case xhtml.TextNode, xhtml.CDATANode:
+			if inCode {
+				return
+			}
+			text := n.Text()
+			for _, m := range workItemTextURLRegexp.FindAllStringSubmatch(text, -1) {
+				id, _ := strconv.Atoi(m[1])
+				add(id, LinkReference)
+			}
+			for _, m := range WorkItemMentionRegexp.FindAllStringSubmatch(text, -1) {
+				id, _ := strconv.Atoi(m[2])
+				add(id, MentionReference)
+			}
+			return
+		case xhtml.ElementNode:
+			switch n.Name {
+			case "ac:structured-macro", "ac:macro":
+				name, _ := n.Attr("ac:name")
+				if t.IsWorkItemMacro(name) {
+					if id, err := strconv.Atoi(strings.TrimSpace(getParameter(n, "itemID"))); err == nil {
+						add(id, MacroReference)
+					}
+					return
+				}
+			case "a":
+				href, _ := n.Attr("href")
+				if id, ok := findID(WorkItemURLRegexp, href); ok {
+					add(id, LinkReference)
+				}
+			case "ri:url":
+				value, _ := n.Attr("ri:value")
+				if id, ok := findID(WorkItemURLRegexp, value); ok {
+					add(id, LinkReference)
+				}
+			}
+			inCode = inCode || isCode(n.Name)
+		}
+
+		for _, child := range n.Children {
+			visit(child, inCode)
+		}
+	}
+	visit(doc, false)
+
+	return references, nil
+}
+
+// getWorkItemMacroNames returns names of macros with itemID parameter rendered by work item templates.
+func (t *Templates) getWorkItemMacroNames() map[string]bool {
+	id := 1
+	data := WorkItemData(&workitemtracking.WorkItem{Id: &id, Fields: &map[string]any{}})
+
+	names := make(map[string]bool)
+	for _, kind := range []Kind{Task, Requirement, Feature, Mention} {
+		macro, err := t.Render(kind, data)
+		if err != nil {
+			continue
+		}
+
+		doc, err := xhtml.Parse(macro)
+		if err != nil {
+			continue
+		}
+
+		for _, n := range doc.Find(func(e *xhtml.Node) bool { return e.Name == "ac:structured-macro" || e.Name == "ac:macro" }) {
+			if _, ok := getParameterNode(n, "itemID"); ok {
+				name, _ := n.Attr("ac:name")
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// isCode reports whether the text of the element is code, link or macro parameter, where mentions are not recognized.
+func isCode(name string) bool {
+	switch name {
+	case "a", "pre", "code", "ac:parameter", "ac:plain-text-body", "ac:plain-text-link-body":
+		return true
+	}
+	return false
+}
+
+func getParameter(macro *xhtml.Node, name string) string {
+	if p, ok := getParameterNode(macro, name); ok {
+		return p.Text()
+	}
+	return ""
+}
+
+func getParameterNode(macro *xhtml.Node, name string) (*xhtml.Node, bool) {
+	for _, p := range macro.Elements() {
+		if n, _ := p.Attr("ac:name"); p.Name == "ac:parameter" && n == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func findID(re *regexp.Regexp, value string) (int, bool) {
+	m := re.FindStringSubmatch(value)
+	if m == nil {
+		return 0, false
+	}
+	id, err := strconv.Atoi(m[1])
+	return id, err == nil
+}
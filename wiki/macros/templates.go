@@ -0,0 +1,196 @@
+// Package macros renders and recognizes Confluence macros referencing TFS work items and pull requests.
+package macros
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"text/template"
+
+	"tasker/tfs/workitem"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v6/workitemtracking"
+)
+
+// Kind is a name of the macro template.
+type Kind string
+
+const (
+	// Task is the macro of the task in the tasks table.
+	Task Kind = "task"
+	// Requirement is the macro of the requirement created for tech debt page.
+	Requirement Kind = "requirement"
+	// Feature is the macro of the feature on its planning page.
+	Feature Kind = "feature"
+	// PullRequest is the link to the pull request.
+	PullRequest Kind = "pr"
+	// Status is the status badge, e.g. state of the work item.
+	Status Kind = "status"
+	// Mention is the inline macro replacing #123 mentions in descriptions.
+	Mention Kind = "mention"
+)
+
+// Kinds are all known macro templates.
+var Kinds = []Kind{Task, Requirement, Feature, PullRequest, Status, Mention}
+
+// Template configures the macro template, Path to the template file has priority over inline Text.
+type Template struct {
+	Path string `mapstructure:"path"`
+	Text string `mapstructure:"template"`
+}
+
+// Data is passed to macro templates.
+type Data struct {
+	// ID is the work item or pull request ID.
+	ID    int
+	Title string
+	URL   string
+	// Status and Colour are used by status badge, colour is one of Grey, Red, Yellow, Green, Blue.
+	Status string
+	Colour string
+	// Task is the work item the macro is rendered for, it is kept for templates written for 'sync' ({{.Task.Id}}).
+	Task *workitemtracking.WorkItem
+}
+
+func (d Data) NewUUID() string {
+	return uuid.NewString()
+}
+
+// WorkItemData returns data of the work item macro.
+func WorkItemData(w *workitemtracking.WorkItem) Data {
+	return Data{
+		ID:     *w.Id,
+		Title:  workitem.GetTitle(w),
+		Status: workitem.GetState(w),
+		Task:   w,
+	}
+}
+
+var defaultTemplates = map[Kind]string{
+	Task: `<div class="content-wrapper">
+			<p>
+				<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1" ac:macro-id="{{.NewUUID}}">
+					<ac:parameter ac:name="itemID">{{.ID}}</ac:parameter>
+					<ac:parameter ac:name="host">1</ac:parameter>
+					<ac:parameter ac:name="assigned">true</ac:parameter>
+					<ac:parameter ac:name="title">false</ac:parameter>
+					<ac:parameter ac:name="type">false</ac:parameter>
+					<ac:parameter ac:name="status">true</ac:parameter>
+				</ac:structured-macro>
+			</p>
+		</div>`,
+	Requirement: `<p>
+	<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1" ac:macro-id="{{.NewUUID}}">
+		<ac:parameter ac:name="itemID">{{.ID}}</ac:parameter>
+		<ac:parameter ac:name="host">1</ac:parameter>
+		<ac:parameter ac:name="assigned">true</ac:parameter>
+		<ac:parameter ac:name="status">true</ac:parameter>
+		<ac:parameter ac:name="boardColumn">true</ac:parameter>
+	</ac:structured-macro>
+</p>
+`,
+	Feature: `<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1" ac:macro-id="{{.NewUUID}}">` +
+		`<ac:parameter ac:name="itemID">{{.ID}}</ac:parameter>` +
+		`<ac:parameter ac:name="host">1</ac:parameter>` +
+		`<ac:parameter ac:name="title">true</ac:parameter>` +
+		`<ac:parameter ac:name="status">true</ac:parameter>` +
+		`</ac:structured-macro>`,
+	PullRequest: `<a href="{{escape .URL}}">PR {{.ID}}{{if .Title}} {{escape .Title}}{{end}}</a>`,
+	Status: `<ac:structured-macro ac:name="status" ac:schema-version="1">` +
+		`<ac:parameter ac:name="colour">{{if .Colour}}{{escape .Colour}}{{else}}Grey{{end}}</ac:parameter>` +
+		`<ac:parameter ac:name="title">{{escape .Status}}</ac:parameter>` +
+		`</ac:structured-macro>`,
+	Mention: `<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1">` +
+		`<ac:parameter ac:name="itemID">{{.ID}}</ac:parameter>` +
+		`<ac:parameter ac:name="host">1</ac:parameter>` +
+		`<ac:parameter ac:name="title">true</ac:parameter>` +
+		`<ac:parameter ac:name="status">true</ac:parameter>` +
+		`</ac:structured-macro>`,
+}
+
+// Templates renders macros by kind.
+type Templates struct {
+	templates map[Kind]*template.Template
+	// workItemMacros are names of work item macros rendered by the templates.
+	workItemMacros map[string]bool
+}
+
+var defaults = mustNew(nil)
+
+// Default returns built-in templates.
+func Default() *Templates {
+	return defaults
+}
+
+// New parses configured templates by kind names, built-in templates are used for not configured kinds.
+func New(config map[string]Template) (*Templates, error) {
+	t := &Templates{templates: make(map[Kind]*template.Template, len(Kinds))}
+
+	for name := range config {
+		if !isKnownKind(Kind(name)) {
+			return nil, fmt.Errorf("unknown macro template %q", name)
+		}
+	}
+
+	for _, kind := range Kinds {
+		text := defaultTemplates[kind]
+		if c, ok := config[string(kind)]; ok {
+			switch {
+			case strings.TrimSpace(c.Path) != "":
+				content, err := os.ReadFile(c.Path)
+				if err != nil {
+					return nil, err
+				}
+				text = string(content)
+			case c.Text != "":
+				text = c.Text
+			}
+		}
+
+		tpl, err := template.New(string(kind)).Funcs(template.FuncMap{
+			"escape": html.EscapeString,
+		}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("macro template %q: %w", kind, err)
+		}
+		t.templates[kind] = tpl
+	}
+	t.workItemMacros = t.getWorkItemMacroNames()
+
+	return t, nil
+}
+
+func mustNew(config map[string]Template) *Templates {
+	t, err := New(config)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func isKnownKind(kind Kind) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders the macro, templates are safe for concurrent use.
+func (t *Templates) Render(kind Kind, data Data) (string, error) {
+	tpl, ok := t.templates[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown macro template %q", kind)
+	}
+
+	var result bytes.Buffer
+	err := tpl.Execute(&result, data)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,305 @@
+package wiki
+
+import (
+	"strconv"
+	"strings"
+
+	"tasker/markdown"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// alertMacros are Confluence macros of GitHub alerts (> [!NOTE]).
+var alertMacros = map[string]string{
+	"NOTE":      "info",
+	"IMPORTANT": "note",
+	"TIP":       "tip",
+	"WARNING":   "warning",
+	"CAUTION":   "warning",
+}
+
+// codeLanguages are names of code macro languages by Markdown aliases, other languages are passed as is.
+var codeLanguages = map[string]string{
+	"javascript": "js",
+	"python":     "py",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"csharp":     "c#",
+	"cs":         "c#",
+	"c":          "cpp",
+	"c++":        "cpp",
+	"yaml":       "yml",
+	"html":       "xml",
+	"ps1":        "powershell",
+	"golang":     "go",
+}
+
+// MarkdownToNativeStorage converts CommonMark/GFM Markdown into native storage format, unlike the markdown macro
+// the content is searchable and editable: code blocks become code macros, task lists become Confluence tasks,
+// GitHub alerts (> [!NOTE]) become info, tip, note and warning macros and images become ac:image.
+func MarkdownToNativeStorage(source string) (string, error) {
+	content, err := markdown.ToHTML(source)
+	if err != nil {
+		return "", err
+	}
+
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	body := wrapHTMLNodes(nodes)
+	c := nativeStorageConverter{}
+	c.convertChildren(body)
+	return renderHTMLChildren(body), nil
+}
+
+type nativeStorageConverter struct {
+	// taskID numbers tasks of the page.
+	taskID int
+}
+
+func (c *nativeStorageConverter) convertChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		// child may be replaced
+		next := child.NextSibling
+		if child.Type == html.ElementNode {
+			c.convert(child)
+		}
+		child = next
+	}
+}
+
+func (c *nativeStorageConverter) convert(n *html.Node) {
+	switch n.DataAtom {
+	case atom.Pre:
+		replaceHTMLNode(n, renderCodeMacro(n))
+		return
+	case atom.Img:
+		replaceHTMLNode(n, renderStorageImage(n, `<ri:url ri:value="`+html.EscapeString(getHTMLAttr(n, "src"))+`" />`))
+		return
+	case atom.Input:
+		// checkbox of the list item mixed with usual items
+		if getHTMLAttr(n, "type") == "checkbox" {
+			replaceHTMLNode(n, checkboxText(n))
+		}
+		return
+	}
+
+	if n.DataAtom == atom.Blockquote {
+		if name, ok := removeAlertMarker(n); ok {
+			c.convertChildren(n)
+			replaceHTMLNode(n, `<ac:structured-macro ac:name="`+name+`" ac:schema-version="1"><ac:rich-text-body>`+
+				renderHTMLChildren(n)+`</ac:rich-text-body></ac:structured-macro>`)
+			return
+		}
+	}
+
+	if n.DataAtom == atom.Ul && isTaskList(n) {
+		replaceHTMLNode(n, c.renderTaskList(n))
+		return
+	}
+
+	if n.DataAtom == atom.Table {
+		normalizeTable(n)
+	}
+
+	c.convertChildren(n)
+}
+
+// renderCodeMacro returns code macro of the code block, language is taken from the language-* class.
+func renderCodeMacro(pre *html.Node) string {
+	code := pre
+	if child := firstElementChild(pre); child != nil && child.DataAtom == atom.Code {
+		code = child
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<ac:structured-macro ac:name="code" ac:schema-version="1">`)
+	for _, class := range strings.Fields(getHTMLAttr(code, "class")) {
+		if language, ok := strings.CutPrefix(class, "language-"); ok {
+			if alias, ok := codeLanguages[strings.ToLower(language)]; ok {
+				language = alias
+			}
+			sb.WriteString(`<ac:parameter ac:name="language">` + html.EscapeString(language) + `</ac:parameter>`)
+			break
+		}
+	}
+
+	text := strings.TrimSuffix(htmlNodeText(code), "\n")
+	sb.WriteString(`<ac:plain-text-body><![CDATA[` + strings.ReplaceAll(text, "]]>", "]]]]><![CDATA[>") + `]]></ac:plain-text-body>`)
+	sb.WriteString(`</ac:structured-macro>`)
+	return sb.String()
+}
+
+// removeAlertMarker removes [!NOTE] marker of GitHub alert from the blockquote and returns the macro name.
+func removeAlertMarker(blockquote *html.Node) (string, bool) {
+	p := firstElementChild(blockquote)
+	if p == nil || p.DataAtom != atom.P || p.FirstChild == nil || p.FirstChild.Type != html.TextNode {
+		return "", false
+	}
+
+	text := p.FirstChild.Data
+	if !strings.HasPrefix(text, "[!") {
+		return "", false
+	}
+	end := strings.Index(text, "]")
+	if end < 0 {
+		return "", false
+	}
+	name, ok := alertMacros[strings.ToUpper(text[2:end])]
+	if !ok {
+		return "", false
+	}
+
+	p.FirstChild.Data = strings.TrimLeft(text[end+1:], " \t\n")
+	if p.FirstChild.Data == "" {
+		p.RemoveChild(p.FirstChild)
+	}
+	if p.FirstChild == nil {
+		blockquote.RemoveChild(p)
+	}
+	return name, true
+}
+
+// isTaskList reports whether all items of the list start with checkbox.
+func isTaskList(list *html.Node) bool {
+	count := 0
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode {
+			continue
+		}
+		if li.DataAtom != atom.Li || getTaskCheckbox(li) == nil {
+			return false
+		}
+		count++
+	}
+	return count > 0
+}
+
+// getTaskCheckbox returns checkbox of the task list item, it's the first node of tight or loose (<p>) item.
+func getTaskCheckbox(li *html.Node) *html.Node {
+	first := firstElementChild(li)
+	if first != nil && first.DataAtom == atom.P {
+		first = firstElementChild(first)
+	}
+	if first == nil || first.DataAtom != atom.Input || getHTMLAttr(first, "type") != "checkbox" {
+		return nil
+	}
+	return first
+}
+
+func (c *nativeStorageConverter) renderTaskList(list *html.Node) string {
+	var sb strings.Builder
+	sb.WriteString("<ac:task-list>")
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode {
+			continue
+		}
+
+		checkbox := getTaskCheckbox(li)
+		status := "incomplete"
+		if _, ok := getHTMLAttrOK(checkbox, "checked"); ok {
+			status = "complete"
+		}
+
+		// loose item keeps its paragraphs, text after checkbox starts with space
+		parent := checkbox.Parent
+		next := checkbox.NextSibling
+		parent.RemoveChild(checkbox)
+		if next != nil && next.Type == html.TextNode {
+			next.Data = strings.TrimLeft(next.Data, " ")
+		}
+		if parent.DataAtom == atom.P && countElements(li) == 1 {
+			for child := parent.FirstChild; child != nil; child = parent.FirstChild {
+				parent.RemoveChild(child)
+				li.InsertBefore(child, parent)
+			}
+			li.RemoveChild(parent)
+		}
+
+		c.convertChildren(li)
+		c.taskID++
+		sb.WriteString("<ac:task><ac:task-id>" + strconv.Itoa(c.taskID) + "</ac:task-id>" +
+			"<ac:task-status>" + status + "</ac:task-status>" +
+			"<ac:task-body>" + strings.TrimSpace(renderHTMLChildren(li)) + "</ac:task-body></ac:task>")
+	}
+	sb.WriteString("</ac:task-list>")
+	return sb.String()
+}
+
+// normalizeTable moves header rows into the body and replaces alignment attributes with styles.
+func normalizeTable(table *html.Node) {
+	var thead, tbody *html.Node
+	for child := table.FirstChild; child != nil; child = child.NextSibling {
+		switch child.DataAtom {
+		case atom.Thead:
+			thead = child
+		case atom.Tbody:
+			tbody = child
+		}
+	}
+
+	if thead != nil {
+		if tbody == nil {
+			tbody = &html.Node{Type: html.ElementNode, Data: "tbody", DataAtom: atom.Tbody}
+			table.InsertBefore(tbody, thead)
+		}
+		first := tbody.FirstChild
+		for row := thead.FirstChild; row != nil; row = thead.FirstChild {
+			thead.RemoveChild(row)
+			tbody.InsertBefore(row, first)
+		}
+		table.RemoveChild(thead)
+	}
+
+	var align func(n *html.Node)
+	align = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.DataAtom == atom.Table {
+				continue
+			}
+			if child.DataAtom == atom.Th || child.DataAtom == atom.Td {
+				for i, attr := range child.Attr {
+					if attr.Namespace == "" && attr.Key == "align" {
+						child.Attr[i] = html.Attribute{Key: "style", Val: "text-align: " + attr.Val + ";"}
+					}
+				}
+				continue
+			}
+			align(child)
+		}
+	}
+	align(table)
+}
+
+func checkboxText(n *html.Node) string {
+	if _, ok := getHTMLAttrOK(n, "checked"); ok {
+		return "☑"
+	}
+	return "☐"
+}
+
+func firstElementChild(n *html.Node) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			return child
+		}
+		if strings.TrimSpace(child.Data) != "" {
+			return nil
+		}
+	}
+	return nil
+}
+
+func countElements(n *html.Node) int {
+	count := 0
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			count++
+		}
+	}
+	return count
+}
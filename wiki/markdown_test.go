@@ -0,0 +1,70 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MarkdownToNativeStorage(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "heading and paragraph",
+			source:   "## Title\n\nText with `code` and **bold**.\n",
+			expected: "<h2>Title</h2>\n<p>Text with <code>code</code> and <strong>bold</strong>.</p>\n",
+		},
+		{
+			name:   "table",
+			source: "| a | b |\n|:--|--:|\n| 1 | 2 |\n",
+			expected: "<table>\n\n<tbody>\n<tr>\n<th style=\"text-align: left;\">a</th>\n<th style=\"text-align: right;\">b</th>\n</tr>\n\n" +
+				"<tr>\n<td style=\"text-align: left;\">1</td>\n<td style=\"text-align: right;\">2</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name:   "code block",
+			source: "```python\nprint(\"]]>\")\n```\n",
+			expected: `<ac:structured-macro ac:name="code" ac:schema-version="1"><ac:parameter ac:name="language">py</ac:parameter>` +
+				`<ac:plain-text-body><![CDATA[print("]]]]><![CDATA[>")]]></ac:plain-text-body></ac:structured-macro>` + "\n",
+		},
+		{
+			name:   "task list",
+			source: "- [x] done\n- [ ] *todo*\n",
+			expected: `<ac:task-list>` +
+				`<ac:task><ac:task-id>1</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>done</ac:task-body></ac:task>` +
+				`<ac:task><ac:task-id>2</ac:task-id><ac:task-status>incomplete</ac:task-status><ac:task-body><em>todo</em></ac:task-body></ac:task>` +
+				`</ac:task-list>` + "\n",
+		},
+		{
+			name:     "mixed list",
+			source:   "- [x] done\n- item\n",
+			expected: "<ul>\n<li>☑ done</li>\n<li>item</li>\n</ul>\n",
+		},
+		{
+			name:   "alert",
+			source: "> [!WARNING]\n> Be careful\n",
+			expected: `<ac:structured-macro ac:name="warning" ac:schema-version="1"><ac:rich-text-body>` +
+				"\n<p>Be careful</p>\n</ac:rich-text-body></ac:structured-macro>\n",
+		},
+		{
+			name:     "quote",
+			source:   "> [link](a.md)\n",
+			expected: "<blockquote>\n<p><a href=\"a.md\">link</a></p>\n</blockquote>\n",
+		},
+		{
+			name:     "image",
+			source:   "![alt](img/a.png \"T\")\n",
+			expected: `<p><ac:image ac:alt="alt" ac:title="T"><ri:url ri:value="img/a.png" /></ac:image></p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := MarkdownToNativeStorage(tt.source)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
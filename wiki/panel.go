@@ -0,0 +1,65 @@
+package wiki
+
+import (
+	"html"
+	"regexp"
+
+	"tasker/wiki/xhtml"
+)
+
+// SetPanel inserts panel macro with the title at the beginning of the page body or replaces the existing one.
+func SetPanel(body, title, content string) string {
+	panel := `<ac:structured-macro ac:name="panel" ac:schema-version="1">` +
+		`<ac:parameter ac:name="title">` + html.EscapeString(title) + `</ac:parameter>` +
+		`<ac:rich-text-body>` + content + `</ac:rich-text-body>` +
+		`</ac:structured-macro>`
+
+	doc, err := xhtml.Parse(body)
+	if err != nil {
+		return setPanelByRegexp(body, title, panel)
+	}
+
+	for _, macro := range doc.FindByName("ac:structured-macro") {
+		if getMacroName(macro) != "panel" || getMacroParameter(macro, "title") != title {
+			continue
+		}
+
+		// the panel is replaced by its position in the parent, since the body is rendered back as is
+		parent := macro.Parent
+		for i, child := range parent.Children {
+			if child != macro {
+				continue
+			}
+
+			replacement := &xhtml.Node{Type: xhtml.DocumentNode}
+			err = replacement.SetInnerXML(panel)
+			if err != nil {
+				return setPanelByRegexp(body, title, panel)
+			}
+
+			children := append([]*xhtml.Node{}, parent.Children[:i]...)
+			children = append(children, replacement.Children...)
+			children = append(children, parent.Children[i+1:]...)
+			parent.Children = nil
+			for _, c := range children {
+				parent.AppendChild(c)
+			}
+			return doc.String()
+		}
+	}
+
+	return panel + body
+}
+
+// setPanelByRegexp replaces the panel in the body which can not be parsed, the content of existing panel
+// should not contain macros, otherwise the end of the panel is detected wrong.
+func setPanelByRegexp(body, title, panel string) string {
+	panelRegexp := regexp.MustCompile(`(?s)<ac:structured-macro ac:name="panel"[^>]*>\s*` +
+		`<ac:parameter ac:name="title">` + regexp.QuoteMeta(html.EscapeString(title)) + `</ac:parameter>.*?</ac:structured-macro>`)
+
+	if loc := panelRegexp.FindStringIndex(body); loc != nil {
+		return body[:loc[0]] + panel + body[loc[1]:]
+	}
+
+	return panel + body
+}
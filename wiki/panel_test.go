@@ -0,0 +1,21 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetPanel(t *testing.T) {
+	content := `<p><ac:structured-macro ac:name="status"><ac:parameter ac:name="title">OK</ac:parameter></ac:structured-macro></p>`
+	body := `<p>text</p><ac:structured-macro ac:name="panel"><ac:parameter ac:name="title">Other</ac:parameter><ac:rich-text-body><p>other</p></ac:rich-text-body></ac:structured-macro>`
+
+	first := SetPanel(body, "Status", content)
+	assert.Equal(t, `<ac:structured-macro ac:name="panel" ac:schema-version="1"><ac:parameter ac:name="title">Status</ac:parameter>`+
+		`<ac:rich-text-body>`+content+`</ac:rich-text-body></ac:structured-macro>`+body, first)
+
+	// the panel with macros is replaced as a whole
+	second := SetPanel(first, "Status", "<p>updated</p>")
+	assert.Equal(t, `<ac:structured-macro ac:name="panel" ac:schema-version="1"><ac:parameter ac:name="title">Status</ac:parameter>`+
+		`<ac:rich-text-body><p>updated</p></ac:rich-text-body></ac:structured-macro>`+body, second)
+}
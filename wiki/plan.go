@@ -0,0 +1,59 @@
+package wiki
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+const tasksHeading = "Задачи"
+
+// DefaultTasksTableColumns are the columns of the tasks table recognized by ParseTasksTable.
+var DefaultTasksTableColumns = []string{"Задача", "Описание", "Оценка", "TFS", "Теги"}
+
+// RenderTasksTable renders tasks as the heading followed by the table in the format ParseTasksTable expects.
+// tfsTaskMacro returns content of the TFS column of the task, description should be in storage format already.
+func RenderTasksTable(tasks []*Task, tfsTaskMacro func(t *Task) string) string {
+	var sb strings.Builder
+
+	sb.WriteString("<h2>" + tasksHeading + "</h2>")
+	sb.WriteString("<table><tbody>")
+	writeTasksTableHeader(&sb, DefaultTasksTableColumns)
+	for _, t := range tasks {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%v</td><td>%s</td><td>%s</td></tr>",
+			html.EscapeString(t.Title),
+			t.Description,
+			t.Estimate,
+			tfsTaskMacro(t),
+			html.EscapeString(t.GetTagsString()),
+		))
+	}
+	sb.WriteString("</tbody></table>")
+
+	return sb.String()
+}
+
+// RenderEmptyTasksTable renders the heading followed by the tasks table with the columns and a single empty row to fill in.
+func RenderEmptyTasksTable(columns []string) string {
+	if len(columns) == 0 {
+		columns = DefaultTasksTableColumns
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("<h2>" + tasksHeading + "</h2>")
+	sb.WriteString("<table><tbody>")
+	writeTasksTableHeader(&sb, columns)
+	sb.WriteString("<tr>" + strings.Repeat("<td><br/></td>", len(columns)) + "</tr>")
+	sb.WriteString("</tbody></table>")
+
+	return sb.String()
+}
+
+func writeTasksTableHeader(sb *strings.Builder, columns []string) {
+	sb.WriteString("<tr>")
+	for _, column := range columns {
+		sb.WriteString("<th>" + html.EscapeString(column) + "</th>")
+	}
+	sb.WriteString("</tr>")
+}
@@ -0,0 +1,114 @@
+package wiki
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// contentProperty is a JSON value stored with the page by key.
+type contentProperty struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+type contentPropertyResult struct {
+	Results []contentProperty `json:"results"`
+}
+
+// GetContentProperty reads the property of the page into value, reports whether the page has the property.
+func (a *API) GetContentProperty(pageID, key string, value any) (bool, error) {
+	p, err := a.getContentProperty(pageID, key)
+	if err != nil || p == nil {
+		return false, err
+	}
+	return true, json.Unmarshal(p.Value, value)
+}
+
+// SetContentProperty creates or updates the property of the page.
+func (a *API) SetContentProperty(pageID, key string, value any) error {
+	p, err := a.getContentProperty(pageID, key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	request := map[string]any{
+		"key":   key,
+		"value": json.RawMessage(data),
+	}
+
+	endpoint, err := getContentPropertyEndpoint(pageID, "")
+	if err != nil {
+		return err
+	}
+
+	method := "POST"
+	if p != nil {
+		method = "PUT"
+		request["version"] = map[string]any{"number": p.Version.Number + 1}
+		endpoint, err = getContentPropertyEndpoint(pageID, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = a.SendAnyContentRequest(endpoint, method, request)
+	return err
+}
+
+// getContentProperty returns nil if the page has no property, properties are listed since missing one is reported by 404.
+func (a *API) getContentProperty(pageID, key string) (*contentProperty, error) {
+	endpoint, err := getContentPropertyEndpoint(pageID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("expand", "version")
+	query.Set("limit", "200")
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := a.Request(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result contentPropertyResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Results {
+		if result.Results[i].Key == key {
+			return &result.Results[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func getContentPropertyEndpoint(pageID, key string) (*url.URL, error) {
+	endpoint, err := getAPIBaseAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	address := endpoint + "/content/" + pageID + "/property"
+	if key != "" {
+		address += "/" + url.PathEscape(key)
+	}
+	return url.ParseRequestURI(address)
+}
@@ -0,0 +1,461 @@
+package wiki
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tasker/wiki/xhtml"
+)
+
+// PublishPropertyKey is the key of the page property written by 'wiki publish'.
+const PublishPropertyKey = "tasker-publish"
+
+// PublishProperty is stored with the published page, so unchanged pages are skipped and pages of removed files are found.
+type PublishProperty struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+// directoryPageBody is the body of directory page without index file, it lists child pages.
+const directoryPageBody = `<ac:structured-macro ac:name="children" ac:schema-version="2" />`
+
+// indexFileNames are files with content of the directory page.
+var indexFileNames = []string{"index.md", "readme.md"}
+
+// PublishPage is a page of the published Markdown directory.
+type PublishPage struct {
+	// Path is the slash separated path of the file or directory relative to the published directory, "." for the directory itself.
+	Path string
+	// File is the slash separated path of the Markdown file of the page, empty for directory without index file.
+	File  string
+	Title string
+	// Body is the content of the page in storage format.
+	Body string
+	// Attachments are paths of files referenced by the page by attachment names.
+	Attachments map[string]string
+	// Hash is changed when title, body or attached files are changed.
+	Hash     string
+	Children []*PublishPage
+}
+
+// Walk calls fn for the page and all its descendants, parents are visited before their children,
+// so pages can be created in the walking order.
+func (p *PublishPage) Walk(fn func(page, parent *PublishPage) error) error {
+	return p.walk(fn, nil)
+}
+
+func (p *PublishPage) walk(fn func(page, parent *PublishPage) error, parent *PublishPage) error {
+	err := fn(p, parent)
+	if err != nil {
+		return err
+	}
+	for _, child := range p.Children {
+		err = child.walk(fn, p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type PublishOptions struct {
+	convert func(source string) (string, error)
+}
+
+type PublishOption func(options *PublishOptions)
+
+// PublishConverter sets the function converting Markdown into storage format, MarkdownToNativeStorage is used by default.
+func PublishConverter(fn func(source string) (string, error)) PublishOption {
+	return func(options *PublishOptions) { options.convert = fn }
+}
+
+func getPublishOptions(opts ...PublishOption) PublishOptions {
+	options := PublishOptions{convert: MarkdownToNativeStorage}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// ReadPublishTree reads the directory of Markdown files as a page tree: each file is a page,
+// each subdirectory is a page with content of its index.md or README.md, the directory itself is the root page.
+// The root page has the title of the target page, titles of other pages are taken from the leading level 1 heading
+// (it's removed from the body) or from file names.
+// Relative links to Markdown files and directories become links to their pages,
+// other referenced files and images become attachments.
+func ReadPublishTree(dir, rootTitle string, opts ...PublishOption) (*PublishPage, error) {
+	options := getPublishOptions(opts...)
+
+	root, err := readPublishDir(dir, ".")
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no markdown files in %s", dir)
+	}
+	root.Title = rootTitle
+
+	// pages by paths of their files and directories, so links can be resolved before pages are converted
+	pages := make(map[string]*PublishPage)
+	err = root.Walk(func(page, _ *PublishPage) error {
+		pages[page.Path] = page
+		if page.File != "" {
+			pages[page.File] = page
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// titles are read first, links refer to pages by titles
+	titles := make(map[string]string)
+	err = root.Walk(func(page, parent *PublishPage) error {
+		if parent != nil && page.File != "" {
+			source, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(page.File)))
+			if err != nil {
+				return err
+			}
+			if title := getMarkdownTitle(string(source)); title != "" {
+				page.Title = title
+			}
+		}
+		if other, ok := titles[page.Title]; ok {
+			return fmt.Errorf("%s and %s have the same title '%s'", other, page.Path, page.Title)
+		}
+		titles[page.Title] = page.Path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = root.Walk(func(page, parent *PublishPage) error {
+		return readPublishPage(dir, page, parent == nil, pages, options)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// readPublishDir returns the directory page, nil if the directory has no Markdown files.
+func readPublishDir(dir, relPath string) (*PublishPage, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PublishPage{
+		Path:  relPath,
+		Title: path.Base(relPath),
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		childPath := path.Join(relPath, name)
+		switch {
+		case entry.IsDir():
+			child, err := readPublishDir(dir, childPath)
+			if err != nil {
+				return nil, err
+			}
+			if child != nil {
+				page.Children = append(page.Children, child)
+			}
+		case !isMarkdownFile(name):
+		case page.File == "" && isIndexFile(name):
+			page.File = childPath
+		default:
+			page.Children = append(page.Children, &PublishPage{
+				Path:  childPath,
+				File:  childPath,
+				Title: strings.TrimSuffix(name, path.Ext(name)),
+			})
+		}
+	}
+
+	if page.File == "" && len(page.Children) == 0 {
+		return nil, nil
+	}
+	return page, nil
+}
+
+// readPublishPage converts the page file into storage format and computes the hash.
+func readPublishPage(dir string, page *PublishPage, isRoot bool, pages map[string]*PublishPage, options PublishOptions) error {
+	page.Body = directoryPageBody
+	page.Attachments = make(map[string]string)
+
+	if page.File != "" {
+		source, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(page.File)))
+		if err != nil {
+			return err
+		}
+
+		body, err := options.convert(string(source))
+		if err != nil {
+			return fmt.Errorf("%s: %w", page.File, err)
+		}
+
+		doc, err := xhtml.Parse(body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", page.File, err)
+		}
+
+		if !isRoot && getMarkdownTitle(string(source)) != "" {
+			removeTitleHeading(doc)
+		}
+
+		r := publishReferences{dir: dir, page: page, pages: pages}
+		r.replace(doc)
+		page.Body = doc.String()
+	}
+
+	hash, err := getPublishHash(dir, page)
+	if err != nil {
+		return err
+	}
+	page.Hash = hash
+	return nil
+}
+
+// publishReferences replaces relative links and images of the page.
+type publishReferences struct {
+	dir   string
+	page  *PublishPage
+	pages map[string]*PublishPage
+}
+
+func (r *publishReferences) replace(n *xhtml.Node) {
+	for _, child := range n.Children {
+		if child.Type != xhtml.ElementNode {
+			continue
+		}
+
+		switch child.Name {
+		case "a":
+			r.replaceLink(child)
+		case "img":
+			r.replaceImage(child)
+		case "ac:image":
+			r.replaceImageURL(child)
+		default:
+			r.replace(child)
+		}
+	}
+}
+
+func (r *publishReferences) replaceLink(n *xhtml.Node) {
+	href, _ := n.Attr("href")
+	target, fragment, ok := r.resolve(href)
+	if !ok {
+		r.replace(n)
+		return
+	}
+
+	link := &xhtml.Node{Type: xhtml.ElementNode, Name: "ac:link"}
+	if page, ok := r.pages[target]; ok {
+		if fragment != "" {
+			link.SetAttr("ac:anchor", fragment)
+		}
+		link.AppendChild(&xhtml.Node{
+			Type:        xhtml.ElementNode,
+			Name:        "ri:page",
+			Attrs:       []xhtml.Attr{{Name: "ri:content-title", Value: page.Title}},
+			SelfClosing: true,
+		})
+	} else if r.isFile(target) {
+		link.AppendChild(&xhtml.Node{
+			Type:        xhtml.ElementNode,
+			Name:        "ri:attachment",
+			Attrs:       []xhtml.Attr{{Name: "ri:filename", Value: r.attach(target)}},
+			SelfClosing: true,
+		})
+	} else {
+		r.replace(n)
+		return
+	}
+
+	body := &xhtml.Node{Type: xhtml.ElementNode, Name: "ac:link-body"}
+	for _, child := range n.Children {
+		body.AppendChild(child)
+	}
+	link.AppendChild(body)
+	replaceStorageNode(n, link)
+}
+
+func (r *publishReferences) replaceImage(n *xhtml.Node) {
+	src, _ := n.Attr("src")
+	target, _, ok := r.resolve(src)
+	if !ok || !r.isFile(target) {
+		return
+	}
+
+	image := &xhtml.Node{Type: xhtml.ElementNode, Name: "ac:image"}
+	for _, name := range []string{"alt", "title", "width", "height"} {
+		if value, ok := n.Attr(name); ok {
+			image.SetAttr("ac:"+name, value)
+		}
+	}
+	image.AppendChild(&xhtml.Node{
+		Type:        xhtml.ElementNode,
+		Name:        "ri:attachment",
+		Attrs:       []xhtml.Attr{{Name: "ri:filename", Value: r.attach(target)}},
+		SelfClosing: true,
+	})
+	replaceStorageNode(n, image)
+}
+
+// replaceImageURL replaces relative ri:url of the image with the attached file.
+func (r *publishReferences) replaceImageURL(n *xhtml.Node) {
+	for _, resource := range n.Elements() {
+		if resource.Name != "ri:url" {
+			continue
+		}
+
+		value, _ := resource.Attr("ri:value")
+		target, _, ok := r.resolve(value)
+		if ok && r.isFile(target) {
+			replaceStorageNode(resource, &xhtml.Node{
+				Type:        xhtml.ElementNode,
+				Name:        "ri:attachment",
+				Attrs:       []xhtml.Attr{{Name: "ri:filename", Value: r.attach(target)}},
+				SelfClosing: true,
+			})
+		}
+	}
+}
+
+// resolve returns the slash separated path relative to the published directory of the relative reference,
+// references outside the directory are not resolved.
+func (r *publishReferences) resolve(ref string) (string, string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return "", "", false
+	}
+
+	base := r.page.Path
+	if r.page.File != "" {
+		base = path.Dir(r.page.File)
+	}
+	target := path.Join(base, u.Path)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", "", false
+	}
+	return target, u.Fragment, true
+}
+
+func (r *publishReferences) isFile(target string) bool {
+	info, err := os.Stat(filepath.Join(r.dir, filepath.FromSlash(target)))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// attach adds the file to attachments of the page and returns the attachment name,
+// it's the file name unless another file with the same name is attached.
+func (r *publishReferences) attach(target string) string {
+	name := path.Base(target)
+	if other, ok := r.page.Attachments[name]; ok && other != target {
+		name = strings.ReplaceAll(target, "/", "_")
+	}
+	r.page.Attachments[name] = target
+	return name
+}
+
+// removeTitleHeading removes the leading level 1 heading used as the page title.
+func removeTitleHeading(doc *xhtml.Node) {
+	elements := doc.Elements()
+	if len(elements) == 0 || elements[0].Name != "h1" {
+		return
+	}
+
+	h1 := elements[0]
+	for i, child := range doc.Children {
+		if child == h1 {
+			doc.Children = append(doc.Children[:i], doc.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// getMarkdownTitle returns text of the leading level 1 ATX heading.
+func getMarkdownTitle(source string) string {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "# ") {
+			return ""
+		}
+		return strings.TrimSpace(strings.TrimRight(strings.TrimPrefix(line, "# "), "#"))
+	}
+	return ""
+}
+
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+func isIndexFile(name string) bool {
+	for _, indexName := range indexFileNames {
+		if strings.EqualFold(name, indexName) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceStorageNode replaces the node with another one in its parent.
+func replaceStorageNode(old, node *xhtml.Node) {
+	parent := old.Parent
+	for i, child := range parent.Children {
+		if child == old {
+			node.Parent = parent
+			parent.Children[i] = node
+			return
+		}
+	}
+}
+
+// getPublishHash returns hash of the page title, body and content of attached files.
+func getPublishHash(dir string, page *PublishPage) (string, error) {
+	h := sha256.New()
+	_, _ = io.WriteString(h, page.Title+"\x00"+page.Body+"\x00")
+
+	names := make([]string, 0, len(page.Attachments))
+	for name := range page.Attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = io.WriteString(h, name+"\x00")
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(page.Attachments[name])))
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,78 @@
+package wiki
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReadPublishTree(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+	files := map[string]string{
+		"README.md":         "# Docs\n\nSee [guide](guide/setup.md#install) and [notes](notes.md).\n",
+		"notes.md":          "Notes ![diagram](img/diagram.png) [spec](spec.pdf) [site](https://example.com/a.md) [outside](../outside.pdf)\n",
+		"../outside.pdf":    "outside",
+		"guide/setup.md":    "# Setup\n\nBack to [docs](../README.md).\n",
+		"guide/img/a.png":   "a",
+		"img/diagram.png":   "diagram",
+		"spec.pdf":          "spec",
+		"assets/logo.svg":   "logo",
+		".hidden/secret.md": "# Secret\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	root, err := ReadPublishTree(dir, "Target")
+	require.NoError(t, err)
+
+	assert.Equal(t, ".", root.Path)
+	assert.Equal(t, "README.md", root.File)
+	assert.Equal(t, "Target", root.Title)
+	assert.Contains(t, root.Body, "<h1>Docs</h1>")
+	assert.Contains(t, root.Body, `<ac:link ac:anchor="install"><ri:page ri:content-title="Setup" /><ac:link-body>guide</ac:link-body></ac:link>`)
+	assert.Contains(t, root.Body, `<ac:link><ri:page ri:content-title="notes" /><ac:link-body>notes</ac:link-body></ac:link>`)
+
+	require.Len(t, root.Children, 2)
+
+	guide := root.Children[0]
+	assert.Equal(t, "guide", guide.Path)
+	assert.Equal(t, "", guide.File)
+	assert.Equal(t, directoryPageBody, guide.Body)
+
+	require.Len(t, guide.Children, 1)
+	setup := guide.Children[0]
+	assert.Equal(t, "Setup", setup.Title)
+	assert.NotContains(t, setup.Body, "<h1>")
+	assert.Contains(t, setup.Body, `<ri:page ri:content-title="Target" />`)
+
+	notes := root.Children[1]
+	assert.Equal(t, "notes", notes.Title)
+	assert.Contains(t, notes.Body, `<ac:image ac:alt="diagram"><ri:attachment ri:filename="diagram.png" /></ac:image>`)
+	assert.Contains(t, notes.Body, `<ac:link><ri:attachment ri:filename="spec.pdf" /><ac:link-body>spec</ac:link-body></ac:link>`)
+	assert.Contains(t, notes.Body, `<a href="https://example.com/a.md">site</a>`)
+	assert.Contains(t, notes.Body, `<a href="../outside.pdf">outside</a>`)
+	assert.Equal(t, map[string]string{"diagram.png": "img/diagram.png", "spec.pdf": "spec.pdf"}, notes.Attachments)
+
+	// hash depends on content of attached files
+	hash := notes.Hash
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "img", "diagram.png"), []byte("changed"), 0o644))
+	root, err = ReadPublishTree(dir, "Target")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, root.Children[1].Hash)
+	assert.Equal(t, setup.Hash, root.Children[0].Children[0].Hash)
+}
+
+func Test_ReadPublishTree_DuplicateTitles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.md"), []byte("# Same\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.md"), []byte("# Same\n"), 0o644))
+
+	_, err := ReadPublishTree(dir, "Target")
+	assert.ErrorContains(t, err, "a.md and b.md have the same title 'Same'")
+}
@@ -23,6 +23,28 @@ func (a *API) SearchContent(query goconfluence.SearchQuery) (*goconfluence.Searc
 	return a.SendSearchRequest(ep, "GET")
 }
 
+// SearchAllContent returns all content found by CQL query, results are requested by chunks.
+func (a *API) SearchAllContent(cql string) ([]goconfluence.Results, error) {
+	const limit = 200
+
+	var results []goconfluence.Results
+	for start := 0; ; start += limit {
+		result, err := a.SearchContent(goconfluence.SearchQuery{
+			CQL:   cql,
+			Start: start,
+			Limit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result.Results...)
+		if len(result.Results) < limit {
+			return results, nil
+		}
+	}
+}
+
 // addSearchQueryParams adds the defined query parameters
 func addSearchQueryParams(query goconfluence.SearchQuery) *url.Values {
 
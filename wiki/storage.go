@@ -0,0 +1,297 @@
+package wiki
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"tasker/wiki/macros"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToStorage converts HTML fragment (e.g. TFS work item description) into well-formed XHTML accepted by storage format.
+func HTMLToStorage(source string) string {
+	nodes, err := parseHTMLFragment(source)
+	if err != nil {
+		return html.EscapeString(source)
+	}
+	return renderHTMLNodes(nodes)
+}
+
+// TfsImage is an image of TFS HTML stored as TFS attachment.
+type TfsImage struct {
+	URL      string
+	FileName string
+}
+
+type StorageOptions struct {
+	attachImage func(image TfsImage) (string, error)
+	macros      *macros.Templates
+}
+
+type StorageOption func(options *StorageOptions)
+
+// AttachImages sets the function attaching TFS image to the wiki page and returning the file name of the attachment,
+// otherwise images are referenced by URL.
+func AttachImages(fn func(image TfsImage) (string, error)) StorageOption {
+	return func(options *StorageOptions) { options.attachImage = fn }
+}
+
+// MentionMacros sets templates of work item macros replacing mentions, built-in templates are used otherwise.
+func MentionMacros(templates *macros.Templates) StorageOption {
+	return func(options *StorageOptions) { options.macros = templates }
+}
+
+func getStorageOptions(opts ...StorageOption) StorageOptions {
+	options := StorageOptions{macros: macros.Default()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// TfsHTMLToStorage converts TFS HTML (e.g. work item description) into storage format:
+// images become ac:image attached to the page (if AttachImages is set) or referenced by URL,
+// work item mentions (#123 and links to work items) become work-item-tfs macros.
+func TfsHTMLToStorage(source string, opts ...StorageOption) (string, error) {
+	options := getStorageOptions(opts...)
+
+	nodes, err := parseHTMLFragment(source)
+	if err != nil {
+		return "", err
+	}
+
+	body := wrapHTMLNodes(nodes)
+	err = convertTfsHTMLChildren(body, options)
+	if err != nil {
+		return "", err
+	}
+	return renderHTMLChildren(body), nil
+}
+
+func parseHTMLFragment(source string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(source), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+// wrapHTMLNodes appends fragment nodes to the body node, so top level nodes can be replaced as well.
+func wrapHTMLNodes(nodes []*html.Node) *html.Node {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	for _, node := range nodes {
+		body.AppendChild(node)
+	}
+	return body
+}
+
+func renderHTMLChildren(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		_ = html.Render(&sb, child)
+	}
+	return sb.String()
+}
+
+// replaceHTMLNode replaces the node with raw storage format markup.
+func replaceHTMLNode(n *html.Node, markup string) {
+	n.Parent.InsertBefore(&html.Node{Type: html.RawNode, Data: markup}, n)
+	n.Parent.RemoveChild(n)
+}
+
+func renderHTMLNodes(nodes []*html.Node) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		_ = html.Render(&sb, node)
+	}
+	return sb.String()
+}
+
+// convertTfsHTMLNode replaces images and work item mentions in the node and its descendants.
+func convertTfsHTMLNode(n *html.Node, options StorageOptions) error {
+	switch {
+	case n.Type == html.TextNode:
+		return replaceWorkItemMentions(n, options)
+	case n.Type != html.ElementNode:
+		return nil
+	case n.DataAtom == atom.Img:
+		return replaceImage(n, options)
+	case n.DataAtom == atom.A:
+		return replaceLink(n, options)
+	case n.DataAtom == atom.Pre || n.DataAtom == atom.Code:
+		return nil
+	}
+	return convertTfsHTMLChildren(n, options)
+}
+
+func convertTfsHTMLChildren(n *html.Node, options StorageOptions) error {
+	for child := n.FirstChild; child != nil; {
+		// child may be replaced
+		next := child.NextSibling
+		err := convertTfsHTMLNode(child, options)
+		if err != nil {
+			return err
+		}
+		child = next
+	}
+	return nil
+}
+
+// replaceWorkItemMentions splits the text node by #123 mentions replaced with macros.
+func replaceWorkItemMentions(n *html.Node, options StorageOptions) error {
+	matches := macros.WorkItemMentionRegexp.FindAllStringSubmatchIndex(n.Data, -1)
+	if len(matches) == 0 || n.Parent == nil {
+		return nil
+	}
+
+	var nodes []*html.Node
+	last := 0
+	for _, m := range matches {
+		// m[2:4] is the preceding character kept in text, m[4:6] is the ID
+		nodes = append(nodes, &html.Node{Type: html.TextNode, Data: n.Data[last:m[3]]})
+		id, _ := strconv.Atoi(n.Data[m[4]:m[5]])
+		macro, err := options.macros.Render(macros.Mention, macros.Data{ID: id})
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, &html.Node{Type: html.RawNode, Data: macro})
+		last = m[1]
+	}
+	nodes = append(nodes, &html.Node{Type: html.TextNode, Data: n.Data[last:]})
+
+	for _, node := range nodes {
+		n.Parent.InsertBefore(node, n)
+	}
+	n.Parent.RemoveChild(n)
+	return nil
+}
+
+// replaceLink replaces TFS mentions: work item links titled like #123 become macros, user mentions become text.
+func replaceLink(n *html.Node, options StorageOptions) error {
+	if n.Parent == nil {
+		return nil
+	}
+
+	text := strings.TrimSpace(htmlNodeText(n))
+	href := getHTMLAttr(n, "href")
+	_, mention := getHTMLAttrOK(n, "data-vss-mention")
+
+	if m := macros.WorkItemURLRegexp.FindStringSubmatch(href); m != nil && (mention || text == "#"+m[1]) {
+		id, _ := strconv.Atoi(m[1])
+		macro, err := options.macros.Render(macros.Mention, macros.Data{ID: id, URL: href})
+		if err != nil {
+			return err
+		}
+		replaceHTMLNode(n, macro)
+		return nil
+	}
+
+	if mention {
+		n.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: text}, n)
+		n.Parent.RemoveChild(n)
+	}
+	return nil
+}
+
+func replaceImage(n *html.Node, options StorageOptions) error {
+	src := getHTMLAttr(n, "src")
+	if src == "" || n.Parent == nil {
+		return nil
+	}
+
+	resource := `<ri:url ri:value="` + html.EscapeString(src) + `" />`
+	if image, ok := getTfsImage(src); ok && options.attachImage != nil {
+		fileName, err := options.attachImage(image)
+		if err != nil {
+			return err
+		}
+		resource = `<ri:attachment ri:filename="` + html.EscapeString(fileName) + `" />`
+	}
+
+	replaceHTMLNode(n, renderStorageImage(n, resource))
+	return nil
+}
+
+// renderStorageImage returns ac:image of the img element showing the resource (ri:url or ri:attachment).
+func renderStorageImage(n *html.Node, resource string) string {
+	var sb strings.Builder
+	sb.WriteString("<ac:image")
+	for _, name := range []string{"alt", "title", "width", "height"} {
+		if value, ok := getHTMLAttrOK(n, name); ok {
+			sb.WriteString(" ac:" + name + `="` + html.EscapeString(value) + `"`)
+		}
+	}
+	sb.WriteString(">" + resource + "</ac:image>")
+	return sb.String()
+}
+
+// GetTfsImages returns images of TFS HTML stored as TFS attachments.
+func GetTfsImages(source string) ([]TfsImage, error) {
+	nodes, err := parseHTMLFragment(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []TfsImage
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			if image, ok := getTfsImage(getHTMLAttr(n, "src")); ok {
+				images = append(images, image)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+
+	return images, nil
+}
+
+// getTfsImage checks whether the image is TFS attachment, e.g. .../_apis/wit/attachments/<id>?fileName=image.png.
+func getTfsImage(src string) (TfsImage, bool) {
+	u, err := url.Parse(src)
+	if err != nil || !strings.Contains(strings.ToLower(u.Path), "/_apis/wit/attachments/") {
+		return TfsImage{}, false
+	}
+
+	fileName := u.Query().Get("fileName")
+	if fileName == "" {
+		fileName = path.Base(u.Path) + ".png"
+	}
+	return TfsImage{URL: src, FileName: fileName}, true
+}
+
+func getHTMLAttr(n *html.Node, name string) string {
+	value, _ := getHTMLAttrOK(n, name)
+	return value
+}
+
+func getHTMLAttrOK(n *html.Node, name string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Namespace == "" && attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func htmlNodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(htmlNodeText(child))
+	}
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package wiki
+
+import (
+	"testing"
+
+	"tasker/wiki/macros"
+	"tasker/wiki/xhtml"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TfsHTMLToStorage(t *testing.T) {
+	macro, err := macros.Default().Render(macros.Mention, macros.Data{ID: 123})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "work item mentions",
+			source:   `<div>See #123, not a#1 or &amp;#2</div><pre>#5</pre>`,
+			expected: `<div>See ` + macro + `, not a#1 or &amp;#2</div><pre>#5</pre>`,
+		},
+		{
+			name:     "work item links",
+			source:   `<a href="https://tfs.local/P/_workitems/edit/123/" data-vss-mention="version:1.0">#123</a> <a href="https://tfs.local/P/_workitems/edit/123">task</a>`,
+			expected: macro + ` <a href="https://tfs.local/P/_workitems/edit/123">task</a>`,
+		},
+		{
+			name:     "user mention",
+			source:   `<a href="#" data-vss-mention="version:2.0,1">@Ivanov Ivan</a>&nbsp;<br>`,
+			expected: "@Ivanov Ivan <br/>",
+		},
+		{
+			name:     "images",
+			source:   `<img src="https://tfs.local/P/_apis/wit/attachments/5d2e1f2a-0000-0000-0000-000000000000?fileName=image.png" width="200"><img src="https://img.local/a.png">`,
+			expected: `<ac:image ac:width="200"><ri:attachment ri:filename="5d2e1f2a-image.png" /></ac:image><ac:image><ri:url ri:value="https://img.local/a.png" /></ac:image>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := TfsHTMLToStorage(tt.source, AttachImages(func(image TfsImage) (string, error) {
+				return "5d2e1f2a-" + image.FileName, nil
+			}))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+
+			_, err = xhtml.Parse(actual)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_GetTfsImages(t *testing.T) {
+	images, err := GetTfsImages(`<div><img src="https://tfs.local/P/_apis/wit/attachments/5d2e1f2a-0000-0000-0000-000000000000?fileName=a.png"></div>` +
+		`<img src="https://img.local/b.png">`)
+	assert.NoError(t, err)
+	assert.Equal(t, []TfsImage{{
+		URL:      "https://tfs.local/P/_apis/wit/attachments/5d2e1f2a-0000-0000-0000-000000000000?fileName=a.png",
+		FileName: "a.png",
+	}}, images)
+}
@@ -0,0 +1,143 @@
+package wiki
+
+import (
+	"fmt"
+	"strings"
+
+	"tasker/wiki/xhtml"
+)
+
+// TableMarker configures how tasks tables are located on the page. Any of enabled markers is enough.
+type TableMarker struct {
+	// HeadingDistance is the max number of sibling elements between the table and the preceding text with the word "задачи".
+	// Containers like layout cells and macro bodies are looked through, so the heading may be outside of them.
+	HeadingDistance int `mapstructure:"headingDistance"`
+	// Caption enables detection by the table caption with the word "задачи".
+	Caption bool `mapstructure:"caption"`
+	// Macro is the name of the macro wrapping tasks tables, e.g. "details" (page properties).
+	// The name can be followed by the value of 'id' parameter of the macro: "details:tasks".
+	Macro string `mapstructure:"macro"`
+	// Anchor is the name of the anchor macro preceding tasks tables within HeadingDistance elements.
+	Anchor string `mapstructure:"anchor"`
+}
+
+// DefaultTableMarker detects the table by the text right before it.
+var DefaultTableMarker = TableMarker{HeadingDistance: 1}
+
+// TableDetection describes why the table is considered as tasks table or not.
+type TableDetection struct {
+	// Index is the number of the table among all tables of the page, starting with zero.
+	Index    int
+	Detected bool
+	Reason   string
+}
+
+// transparentContainers are looked through when searching for the text preceding the table.
+var transparentContainers = map[string]bool{
+	"ac:layout":           true,
+	"ac:layout-section":   true,
+	"ac:layout-cell":      true,
+	"ac:structured-macro": true,
+	"ac:rich-text-body":   true,
+	"div":                 true,
+	"section":             true,
+}
+
+// detect checks markers of the table.
+func (m TableMarker) detect(table *xhtml.Node) (bool, string) {
+	if m.Caption {
+		for _, caption := range table.Elements() {
+			if caption.Name == "caption" && tasksRegexp.MatchString(caption.Text()) {
+				return true, fmt.Sprintf("caption %q", strings.TrimSpace(caption.Text()))
+			}
+		}
+	}
+
+	if m.Macro != "" {
+		name, id, _ := strings.Cut(m.Macro, ":")
+		for p := table.Parent; p != nil; p = p.Parent {
+			if p.Name == "ac:structured-macro" && getMacroName(p) == name && (id == "" || getMacroParameter(p, "id") == id) {
+				return true, fmt.Sprintf("inside macro %q", m.Macro)
+			}
+		}
+	}
+
+	var found bool
+	var reason string
+	walkPrecedingElements(table, m.HeadingDistance, func(e *xhtml.Node, distance int) bool {
+		if m.Anchor != "" {
+			for _, anchor := range append([]*xhtml.Node{e}, e.FindByName("ac:structured-macro")...) {
+				if getMacroName(anchor) == "anchor" && getMacroParameter(anchor, "") == m.Anchor {
+					found, reason = true, fmt.Sprintf("anchor %q %d element(s) before", m.Anchor, distance)
+					return false
+				}
+			}
+		}
+
+		if tasksRegexp.MatchString(e.Text()) {
+			found, reason = true, fmt.Sprintf("text %q %d element(s) before", cutText(strings.TrimSpace(e.Text()), 40), distance)
+			return false
+		}
+		return true
+	})
+
+	if found {
+		return true, reason
+	}
+	return false, "no marker found"
+}
+
+// walkPrecedingElements calls fn for up to maxDistance elements preceding the node, going out of transparent containers.
+// Walking stops at other tables, so the text of the previous tasks table is not taken as a heading.
+func walkPrecedingElements(node *xhtml.Node, maxDistance int, fn func(e *xhtml.Node, distance int) bool) {
+	distance := 0
+	for current := node; current != nil && current.Type == xhtml.ElementNode && distance < maxDistance; {
+		prev := current.PrevElement()
+		if prev == nil {
+			parent := current.Parent
+			if parent == nil || parent.Type != xhtml.ElementNode || !transparentContainers[parent.Name] {
+				return
+			}
+			current = parent
+			continue
+		}
+
+		if prev.Name == "table" || len(prev.FindByName("table")) > 0 {
+			return
+		}
+
+		distance++
+		if !fn(prev, distance) {
+			return
+		}
+		current = prev
+	}
+}
+
+func getMacroName(macro *xhtml.Node) string {
+	if macro.Name != "ac:structured-macro" {
+		return ""
+	}
+	name, _ := macro.Attr("ac:name")
+	return name
+}
+
+func getMacroParameter(macro *xhtml.Node, name string) string {
+	for _, parameter := range macro.Elements() {
+		if parameter.Name != "ac:parameter" {
+			continue
+		}
+		if parameterName, _ := parameter.Attr("ac:name"); parameterName == name {
+			return parameter.Text()
+		}
+	}
+	return ""
+}
+
+func cutText(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) > maxLength {
+		return string(runes[:maxLength-3]) + "..."
+	}
+	return text
+}
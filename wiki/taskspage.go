@@ -8,18 +8,15 @@ import (
 	"strconv"
 	"strings"
 	"tasker/tasksui"
+	"tasker/wiki/macros"
+	"tasker/wiki/xhtml"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/samber/lo"
 )
 
 var (
-	tasksRegexp    = regexp.MustCompile("(?i).*задач.*")
-	spacesRegexp   = regexp.MustCompile(`\s+(<)|(>)\s+`)
-	tablesRegexp   = regexp.MustCompile(`(?i)\<table(.|\s)+?\</table>`)
-	emoticonRegexp = regexp.MustCompile(`(?i)(\<ac\:emoticon(.|\s)*?)\/\>`)
-	cdataRegexp    = regexp.MustCompile(`(?i)(<!--\[CDATA\[((.|\s)*?)\]\]-->)`)
-	columnsMapping = make(map[int]taskColumn)
+	tasksRegexp  = regexp.MustCompile("(?i).*задач.*")
+	spacesRegexp = regexp.MustCompile(`\s+(<)|(>)\s+`)
 )
 
 type taskColumn int
@@ -38,9 +35,9 @@ type Task struct {
 	Estimate    float32
 	TfsTaskID   int
 	Tags        []string
-	tfsColumn   *goquery.Selection
+	tfsColumn   *xhtml.Node
 	updated     bool
-	tr          *goquery.Selection
+	tr          *xhtml.Node
 }
 
 func (t *Task) GetTitle() string                  { return t.Title }
@@ -79,51 +76,101 @@ func (t *Table) SetTask(tsk tasksui.Task, index int) {
 	t.Tasks[index] = tsk.(*Task)
 }
 
-func (t *Task) Update(html string) {
-	if t.tfsColumn != nil {
-		t.tfsColumn.SetHtml(html)
-		t.updated = true
+// Update replaces content of the TFS column with the markup, the task without TFS column is not changed.
+func (t *Task) Update(html string) error {
+	if t.tfsColumn == nil {
+		return nil
 	}
+
+	err := t.tfsColumn.SetInnerXML(html)
+	if err != nil {
+		return fmt.Errorf("invalid TFS column markup: %w", err)
+	}
+	t.updated = true
+	return nil
 }
 
 func (t *Task) isEmpty() bool {
 	return t.Estimate == 0 && t.TfsTaskID == 0
 }
 
+// TableIndex returns the number of the task table among all tables of the page, starting with zero.
 func (t *Task) TableIndex() int {
-	table := t.Table()
-	indexStr, _ := table.Attr("index")
-	index, err := strconv.Atoi(indexStr)
-	if err == nil {
-		return index
+	index, _, _ := t.position()
+	return index
+}
+
+// position returns numbers of the table, the row and the TFS column cell of the task in the page.
+func (t *Task) position() (int, int, int) {
+	table := t.tr.Closest("table")
+	if table == nil {
+		return -1, -1, -1
 	}
 
-	return -1
+	return slices.Index(t.tr.Root().FindByName("table"), table),
+		slices.Index(table.TableRows(), t.tr),
+		slices.Index(t.tr.RowCells(), t.tfsColumn)
+}
+
+type ParseOptions struct {
+	marker   TableMarker
+	onDetect func(detection TableDetection)
+	macros   *macros.Templates
+}
+
+type ParseOption func(options *ParseOptions)
+
+// TasksTableMarker sets how tasks tables are located, DefaultTableMarker is used by default.
+func TasksTableMarker(marker TableMarker) ParseOption {
+	return func(options *ParseOptions) { options.marker = marker }
+}
+
+// ReportTableDetection sets function called for each table of the page with the reason it is (not) a tasks table.
+func ReportTableDetection(fn func(detection TableDetection)) ParseOption {
+	return func(options *ParseOptions) { options.onDetect = fn }
+}
+
+// WorkItemMacros sets templates recognizing work item macros, built-in templates are used by default.
+func WorkItemMacros(templates *macros.Templates) ParseOption {
+	return func(options *ParseOptions) { options.macros = templates }
 }
 
-func (t *Task) Table() *goquery.Selection {
-	return t.tr.Parent().Parent()
+func getParseOptions(opts ...ParseOption) ParseOptions {
+	options := ParseOptions{marker: DefaultTableMarker, macros: macros.Default()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
 }
 
-func ParseTasksTable(body string) ([]*Task, error) {
-	body = fixMarkup(body)
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+func ParseTasksTable(body string, opts ...ParseOption) ([]*Task, error) {
+	options := getParseOptions(opts...)
+
+	doc, err := xhtml.Parse(body)
 	if err != nil {
 		return nil, err
 	}
 
 	var tasks []*Task
 
-	_ = doc.Find("table").
-		Each(func(i int, s *goquery.Selection) {
-			s.SetAttr("index", fmt.Sprintf("%d", i))
-		}).
-		FilterFunction(func(i int, s *goquery.Selection) bool {
-			return tasksRegexp.MatchString(s.Prev().Text())
-		}).
-		Find("tr").
-		Each(func(i int, tr *goquery.Selection) {
-			tr.Find("th").Each(func(colNum int, th *goquery.Selection) {
+	for i, table := range doc.FindByName("table") {
+		detected, reason := options.marker.detect(table)
+		if options.onDetect != nil {
+			options.onDetect(TableDetection{Index: i, Detected: detected, Reason: reason})
+		}
+		if !detected {
+			continue
+		}
+
+		columnsMapping := make(map[int]taskColumn)
+		for _, tr := range table.TableRows() {
+			cells := tr.RowCells()
+			for colNum, th := range cells {
+				if th.Name != "th" {
+					continue
+				}
 				switch strings.ToLower(th.Text()) {
 				case "задача":
 					columnsMapping[colNum] = titleColumn
@@ -137,17 +184,17 @@ func ParseTasksTable(body string) ([]*Task, error) {
 				case "теги":
 					columnsMapping[colNum] = tagsColumn
 				}
-			})
+			}
 
-			cols := tr.Find("td")
-			if len(cols.Nodes) < len(columnsMapping) {
-				return
+			cols := lo.Filter(cells, func(cell *xhtml.Node, _ int) bool { return cell.Name == "td" })
+			if len(cols) < len(columnsMapping) {
+				continue
 			}
 
 			task := &Task{
 				tr: tr,
 			}
-			cols.Each(func(colNum int, td *goquery.Selection) {
+			for colNum, td := range cols {
 				column, ok := columnsMapping[colNum]
 				if ok {
 					switch column {
@@ -155,13 +202,12 @@ func ParseTasksTable(body string) ([]*Task, error) {
 						title := td.Text()
 						task.Title = strings.TrimSpace(title)
 					case descColumn:
-						html, _ := td.Html()
-						task.Description = removeExtraSpaces(html)
+						task.Description = removeExtraSpaces(td.InnerHTML())
 					case estColumn:
 						floatValue, _ := strconv.ParseFloat(strings.TrimSpace(td.Text()), 32)
 						task.Estimate = float32(floatValue)
 					case tfsColumn:
-						task.TfsTaskID = parseTfsTaskID(td)
+						task.TfsTaskID = parseTfsTaskID(td, options.macros)
 						task.tfsColumn = td
 					case tagsColumn:
 						tagsStr := td.Text()
@@ -170,12 +216,13 @@ func ParseTasksTable(body string) ([]*Task, error) {
 						task.Tags = tags
 					}
 				}
-			})
+			}
 
 			if !task.isEmpty() && task.TfsTaskID != -1 {
 				tasks = append(tasks, task)
 			}
-		})
+		}
+	}
 
 	return tasks, nil
 }
@@ -184,70 +231,48 @@ func removeExtraSpaces(value string) string {
 	return strings.TrimSpace(spacesRegexp.ReplaceAllString(value, "$1$2"))
 }
 
-func parseTfsTaskID(td *goquery.Selection) int {
-	text := td.Find("ac\\:parameter[ac\\:name='itemID']").Text()
-	taskID, err := strconv.Atoi(text)
-	if err != nil {
+// parseTfsTaskID returns ID of the work item referenced by the cell (by macro, link or #123), -1 if the cell has other text.
+func parseTfsTaskID(td *xhtml.Node, templates *macros.Templates) int {
+	references, err := templates.ParseReferences(td.InnerXML())
+	if err != nil || len(references) == 0 {
 		if len(td.Text()) > 0 {
 			return -1
 		}
 		return 0
 	}
-	return taskID
+	return references[0].ID
 }
 
+// UpdatePageContent sets TFS column cells of updated tasks in the page body, the cells are located by position.
 func UpdatePageContent(body string, tasks []*Task) (string, bool, error) {
 	updatedTasks := getUpdatedTasks(tasks)
 	if len(updatedTasks) == 0 {
 		return "", false, nil
 	}
 
-	tables := make(map[int]*goquery.Selection)
-	for _, t := range tasks {
-		tableIndex := t.TableIndex()
+	doc, err := xhtml.Parse(body)
+	if err != nil {
+		return "", false, err
+	}
 
-		if tableIndex == -1 {
+	tables := doc.FindByName("table")
+	for _, t := range updatedTasks {
+		tableIndex, row, col := t.position()
+		if tableIndex == -1 || row == -1 || col == -1 {
 			return "", false, errors.New("table index not found")
 		}
 
-		if _, ok := tables[tableIndex]; !ok {
-			tables[tableIndex] = t.Table()
+		if tableIndex >= len(tables) {
+			return "", false, errors.New("not all tasks tables found")
 		}
-	}
 
-	tablesIndexes := tablesRegexp.FindAllStringIndex(body, -1)
-	maxIndex := getMaxIndex(tables)
-	if maxIndex >= len(tablesIndexes) {
-		return "", false, errors.New("not all tasks tables found")
-	}
-
-	for index, table := range tables {
-		tableElement := table.Clone()
-		tableElement.Empty()
-		tableElement.RemoveAttr("index")
-		table.WrapInnerSelection(tableElement)
-		tableMarkup, _ := table.Html()
-		tableMarkup = restoreMarkup(tableMarkup)
-
-		i := tablesIndexes[index]
-		modifiedBody := body[:i[0]]
-		modifiedBody += tableMarkup
-		modifiedBody += body[i[1]:]
-		body = modifiedBody
-		tablesIndexes = tablesRegexp.FindAllStringIndex(body, -1)
-	}
-
-	return body, true, nil
-}
-
-func getMaxIndex(tables map[int]*goquery.Selection) int {
-	var maxIndex int
-	for index := range tables {
-		if index > maxIndex {
-			maxIndex = index
+		err = tables[tableIndex].SetTableCell(row, col, t.tfsColumn.InnerXML())
+		if err != nil {
+			return "", false, err
 		}
 	}
-	return maxIndex
+
+	return doc.String(), true, nil
 }
 
 func getUpdatedTasks(tasks []*Task) []*Task {
@@ -260,16 +285,6 @@ func getUpdatedTasks(tasks []*Task) []*Task {
 	return updated
 }
 
-func fixMarkup(markup string) string {
-	markup = emoticonRegexp.ReplaceAllString(markup, "$1></ac:emoticon>")
-	return markup
-}
-
-func restoreMarkup(markup string) string {
-	markup = cdataRegexp.ReplaceAllString(markup, "<![CDATA[$2]]>")
-	return markup
-}
-
 func GroupByTable(tasks []*Task) ([]*Table, error) {
 	var tables []*Table
 	m := make(map[int]*Table)
@@ -2,8 +2,11 @@ package wiki
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -135,3 +138,147 @@ func Test_ParseTasks_Page2(t *testing.T) {
 	setNodesToNil(tasks)
 	assert.Equal(t, expected, tasks)
 }
+
+func Test_RenderTasksTable(t *testing.T) {
+	tasks := []*Task{
+		{
+			Title:       "01. Репозиторий & сервис",
+			Description: "<p>Сохранение</p><p>Получение</p>",
+			Estimate:    7,
+			TfsTaskID:   71711,
+			Tags:        []string{"разработка"},
+		},
+		{
+			Title:       "02. Контроллер",
+			Description: "Описание",
+			Estimate:    2.5,
+			TfsTaskID:   71712,
+			Tags:        []string{"разработка", "тестирование"},
+		},
+	}
+
+	body := RenderTasksTable(tasks, func(t *Task) string {
+		return `<p><ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1">` +
+			`<ac:parameter ac:name="itemID">` + strconv.Itoa(t.TfsTaskID) + `</ac:parameter>` +
+			`</ac:structured-macro></p>`
+	})
+
+	parsed, err := ParseTasksTable(body)
+	assert.NoError(t, err)
+
+	setNodesToNil(parsed)
+	assert.Equal(t, tasks, parsed)
+}
+
+func Test_ParseTasks_TableMarkers(t *testing.T) {
+	table := `<table><tbody><tr><th>Задача</th><th>Оценка</th></tr><tr><td>Первая</td><td>2</td></tr></tbody></table>`
+
+	tests := []struct {
+		name   string
+		body   string
+		marker TableMarker
+		found  bool
+	}{
+		{
+			name:   "heading right before",
+			body:   `<h2>Задачи</h2>` + table,
+			marker: DefaultTableMarker,
+			found:  true,
+		},
+		{
+			name:   "heading separated by paragraph",
+			body:   `<h2>Задачи</h2><p>Оценки в часах</p>` + table,
+			marker: DefaultTableMarker,
+			found:  false,
+		},
+		{
+			name:   "heading within distance",
+			body:   `<h2>Задачи</h2><p>Оценки в часах</p>` + table,
+			marker: TableMarker{HeadingDistance: 2},
+			found:  true,
+		},
+		{
+			name:   "heading before previous table",
+			body:   `<h2>Задачи</h2>` + table + `<p>Прочее</p>` + table,
+			marker: TableMarker{HeadingDistance: 5},
+			found:  false,
+		},
+		{
+			name: "layout cell",
+			body: `<h2>Задачи</h2><ac:layout><ac:layout-section ac:type="two_equal"><ac:layout-cell>` + table +
+				`</ac:layout-cell><ac:layout-cell><p>Заметки</p></ac:layout-cell></ac:layout-section></ac:layout>`,
+			marker: DefaultTableMarker,
+			found:  true,
+		},
+		{
+			name: "expand macro",
+			body: `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Задачи бекенда</ac:parameter>` +
+				`<ac:rich-text-body>` + table + `</ac:rich-text-body></ac:structured-macro>`,
+			marker: DefaultTableMarker,
+			found:  true,
+		},
+		{
+			name:   "caption",
+			body:   strings.Replace(table, "<tbody>", "<caption>Задачи</caption><tbody>", 1),
+			marker: TableMarker{Caption: true},
+			found:  true,
+		},
+		{
+			name: "marker macro",
+			body: `<ac:structured-macro ac:name="details"><ac:parameter ac:name="id">tasks</ac:parameter>` +
+				`<ac:rich-text-body>` + table + `</ac:rich-text-body></ac:structured-macro>`,
+			marker: TableMarker{Macro: "details:tasks"},
+			found:  true,
+		},
+		{
+			name: "anchor",
+			body: `<p><ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">tasks</ac:parameter></ac:structured-macro></p>` +
+				`<p>Оценки в часах</p>` + table,
+			marker: TableMarker{HeadingDistance: 2, Anchor: "tasks"},
+			found:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var detections []TableDetection
+			tasks, err := ParseTasksTable(tt.body, TasksTableMarker(tt.marker), ReportTableDetection(func(d TableDetection) {
+				detections = append(detections, d)
+			}))
+			assert.NoError(t, err)
+			assert.NotEmpty(t, detections)
+			assert.Equal(t, tt.found, detections[len(detections)-1].Detected, detections)
+
+			// each table contains single task
+			detected := lo.CountBy(detections, func(d TableDetection) bool { return d.Detected })
+			assert.Len(t, tasks, detected)
+		})
+	}
+}
+
+func Test_UpdatePageContent(t *testing.T) {
+	file, err := os.ReadFile("../testdata/wiki_page_2.html")
+	assert.NoError(t, err)
+	body := string(file)
+
+	tasks, err := ParseTasksTable(body)
+	assert.NoError(t, err)
+
+	err = tasks[0].Update(`<p><ac:structured-macro ac:name="work-item-tfs"><ac:parameter ac:name="itemID">12345</ac:parameter></ac:structured-macro></p>`)
+	assert.NoError(t, err)
+	assert.Error(t, tasks[1].Update(`<p>not closed`))
+
+	updatedBody, modified, err := UpdatePageContent(body, tasks)
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Contains(t, updatedBody, `<ac:plain-text-link-body><![CDATA[классификатора]]></ac:plain-text-link-body>`)
+
+	updatedTasks, err := ParseTasksTable(updatedBody)
+	assert.NoError(t, err)
+	assert.Len(t, updatedTasks, len(tasks))
+	assert.Equal(t, 12345, updatedTasks[0].TfsTaskID)
+	for i := 1; i < len(tasks); i++ {
+		assert.Equal(t, tasks[i].TfsTaskID, updatedTasks[i].TfsTaskID)
+		assert.Equal(t, tasks[i].Description, updatedTasks[i].Description)
+	}
+}
@@ -1,26 +1,14 @@
 package wiki
 
 import (
-	"strconv"
+	"html"
 	"strings"
 
-	"github.com/PuerkitoBio/goquery"
-	"github.com/google/uuid"
-	goconfluence "github.com/virtomize/confluence-go-api"
-)
+	"tasker/wiki/macros"
+	"tasker/wiki/xhtml"
 
-var (
-	tfsTaskStructuredMacroAttributes = map[string]func(value string, t *TfsTask) error{
-		"itemID": func(value string, t *TfsTask) error {
-			parsedValue, err := strconv.Atoi(value)
-			if err != nil {
-				return err
-			}
-
-			t.ItemID = parsedValue
-			return nil
-		},
-	}
+	"github.com/samber/lo"
+	goconfluence "github.com/virtomize/confluence-go-api"
 )
 
 type TfsTask struct {
@@ -41,32 +29,22 @@ func (td *TechDebt) GetUpdatedBody() (string, error) {
 	return td.Body, nil
 }
 
-func (td *TechDebt) AddTfsTask(id int) {
-	html := `<p>
-	<ac:structured-macro ac:name="work-item-tfs" ac:schema-version="1" ac:macro-id="` + uuid.NewString() + `">
-		<ac:parameter ac:name="itemID">` + strconv.Itoa(id) + `</ac:parameter>
-		<ac:parameter ac:name="host">1</ac:parameter>
-		<ac:parameter ac:name="assigned">true</ac:parameter>					
-		<ac:parameter ac:name="status">true</ac:parameter>
-		<ac:parameter ac:name="boardColumn">true</ac:parameter>
-	</ac:structured-macro>
-</p>
-`
-
-	td.Body = html + td.Body
+// AddTfsTask inserts the macro of the created work item at the beginning of the page.
+func (td *TechDebt) AddTfsTask(id int, macro string) {
+	td.Body = macro + td.Body
 	td.TfsTasks = append(td.TfsTasks, TfsTask{
 		ItemID: id,
 	})
 }
 
-func ParseTechDebt(content *goconfluence.Content) (TechDebt, error) {
+func ParseTechDebt(content *goconfluence.Content, opts ...ParseOption) (TechDebt, error) {
 	body := content.Body.Storage.Value
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	doc, err := xhtml.Parse(body)
 	if err != nil {
 		return TechDebt{}, err
 	}
 
-	tasks, err := parseTfsTask(doc)
+	tasks, err := parseTfsTasks(body, getParseOptions(opts...).macros)
 	if err != nil {
 		return TechDebt{}, err
 	}
@@ -85,39 +63,29 @@ func ParseTechDebt(content *goconfluence.Content) (TechDebt, error) {
 }
 
 func getWikiPageLink(content *goconfluence.Content) string {
-	url := content.Links.Base + content.Links.WebUI
-	return `<div><a href="` + url + `">` + content.Title + `</a><br></div>`
+	return `<div><a href="` + GetPageURL(content) + `">` + html.EscapeString(content.Title) + `</a><br/></div>`
 }
 
-func ParseTfsTasks(content *goconfluence.Content) ([]TfsTask, error) {
-	body := content.Body.Storage.Value
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
-	if err != nil {
-		return nil, err
+// GetPageURL returns absolute web URL of the wiki page.
+func GetPageURL(content *goconfluence.Content) string {
+	if content.Links == nil {
+		return ""
 	}
+	return content.Links.Base + content.Links.WebUI
+}
 
-	return parseTfsTask(doc)
+// ParseTfsTasks returns work items referenced by the page: work item macros, links and #123 mentions.
+func ParseTfsTasks(content *goconfluence.Content, opts ...ParseOption) ([]TfsTask, error) {
+	return parseTfsTasks(content.Body.Storage.Value, getParseOptions(opts...).macros)
 }
 
-func parseTfsTask(doc *goquery.Document) ([]TfsTask, error) {
-	var parseErr error
-	var tasks []TfsTask
-	doc.Find("ac\\:structured-macro[ac\\:name='work-item-tfs']").
-		Each(func(i int, sm *goquery.Selection) {
-			task := TfsTask{}
-			sm.Find("ac\\:parameter").
-				Each(func(i int, p *goquery.Selection) {
-					if attrName, exists := p.Attr("ac:name"); exists {
-						if parseAttr, ok := tfsTaskStructuredMacroAttributes[attrName]; ok {
-							err := parseAttr(p.Text(), &task)
-							if err != nil {
-								parseErr = err
-							}
-						}
-					}
-				})
-			tasks = append(tasks, task)
-		})
-
-	return tasks, parseErr
+func parseTfsTasks(body string, templates *macros.Templates) ([]TfsTask, error) {
+	references, err := templates.ParseReferences(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(references, func(r macros.Reference, _ int) TfsTask {
+		return TfsTask{ItemID: r.ID}
+	}), nil
 }
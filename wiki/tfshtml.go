@@ -0,0 +1,513 @@
+package wiki
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tasker/wiki/macros"
+	"tasker/wiki/xhtml"
+
+	"github.com/spf13/viper"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// StorageUser is a user mentioned in storage format content, only one of identifiers is usually set.
+type StorageUser struct {
+	Key       string
+	AccountID string
+	Username  string
+}
+
+// Query returns the user query accepted by goconfluence.API.User.
+func (u StorageUser) Query() string {
+	switch {
+	case u.Key != "":
+		return "key:" + u.Key
+	case u.AccountID != "":
+		return "accountId:" + u.AccountID
+	default:
+		return u.Username
+	}
+}
+
+// StorageImage is an image attached to the wiki page and referenced by storage format content.
+type StorageImage struct {
+	// PageID is the ID of the page the image is attached to, it is empty when the page is referenced by title.
+	PageID    string
+	PageTitle string
+	SpaceKey  string
+	FileName  string
+	// URL is the absolute download URL of the image, it is empty when page ID is unknown.
+	URL string
+}
+
+type TfsHTMLOptions struct {
+	resolveUser func(user StorageUser) string
+	uploadImage func(image StorageImage) (string, error)
+	workItemURL func(id int) string
+	macros      *macros.Templates
+}
+
+type TfsHTMLOption func(options *TfsHTMLOptions)
+
+// ResolveUsers sets the function returning the name of the mentioned user, user key is shown otherwise.
+func ResolveUsers(fn func(user StorageUser) string) TfsHTMLOption {
+	return func(options *TfsHTMLOptions) { options.resolveUser = fn }
+}
+
+// UploadImages sets the function uploading attached image and returning its new URL,
+// otherwise images are referenced by wiki download URL.
+func UploadImages(fn func(image StorageImage) (string, error)) TfsHTMLOption {
+	return func(options *TfsHTMLOptions) { options.uploadImage = fn }
+}
+
+// WorkItemURLs sets the function returning URL of the work item, work-item-tfs macros are converted to links to it.
+func WorkItemURLs(fn func(id int) string) TfsHTMLOption {
+	return func(options *TfsHTMLOptions) { options.workItemURL = fn }
+}
+
+// TfsHTMLMacros sets templates recognizing work item macros, built-in templates are used by default.
+func TfsHTMLMacros(templates *macros.Templates) TfsHTMLOption {
+	return func(options *TfsHTMLOptions) { options.macros = templates }
+}
+
+func getTfsHTMLOptions(opts ...TfsHTMLOption) TfsHTMLOptions {
+	options := TfsHTMLOptions{macros: macros.Default()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// emoticons maps names of Confluence emoticons to emoji.
+var emoticons = map[string]string{
+	"smile":        "🙂",
+	"sad":          "🙁",
+	"cheeky":       "😛",
+	"laugh":        "😀",
+	"wink":         "😉",
+	"thumbs-up":    "👍",
+	"thumbs-down":  "👎",
+	"information":  "ℹ️",
+	"tick":         "✔️",
+	"cross":        "❌",
+	"warning":      "⚠️",
+	"plus":         "➕",
+	"minus":        "➖",
+	"question":     "❓",
+	"light-on":     "💡",
+	"light-off":    "💡",
+	"yellow-star":  "⭐",
+	"red-star":     "⭐",
+	"green-star":   "⭐",
+	"blue-star":    "⭐",
+	"heart":        "❤️",
+	"broken-heart": "💔",
+}
+
+// tfsHTMLConverter renders storage format nodes as HTML.
+type tfsHTMLConverter struct {
+	options  TfsHTMLOptions
+	baseURL  string
+	pageID   string
+	spaceKey string
+	sb       strings.Builder
+}
+
+// StorageToTfsHTML converts storage format content of the wiki page into HTML suitable for TFS work item fields:
+// links are made absolute, user mentions are replaced with names, code macros become <pre>, status macros become text,
+// images are uploaded (if UploadImages is set) and inlined, other macros are replaced with their bodies.
+func StorageToTfsHTML(storage string, page *goconfluence.Content, opts ...TfsHTMLOption) (string, error) {
+	doc, err := xhtml.Parse(storage)
+	if err != nil {
+		return "", err
+	}
+
+	c := &tfsHTMLConverter{
+		options: getTfsHTMLOptions(opts...),
+		baseURL: getPageBaseURL(page),
+		pageID:  page.ID,
+	}
+	if page.Space != nil {
+		c.spaceKey = page.Space.Key
+	}
+
+	err = c.writeChildren(doc)
+	if err != nil {
+		return "", err
+	}
+	return c.sb.String(), nil
+}
+
+// getPageBaseURL returns the base address of the wiki the page belongs to.
+func getPageBaseURL(page *goconfluence.Content) string {
+	if page.Links != nil && page.Links.Base != "" {
+		return strings.TrimRight(page.Links.Base, "/")
+	}
+	return strings.TrimRight(viper.GetString("wikiBaseAddress"), "/")
+}
+
+func (c *tfsHTMLConverter) writeChildren(n *xhtml.Node) error {
+	for _, child := range n.Children {
+		err := c.write(child)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *tfsHTMLConverter) write(n *xhtml.Node) error {
+	switch n.Type {
+	case xhtml.TextNode:
+		c.sb.WriteString(html.EscapeString(html.UnescapeString(n.Data)))
+	case xhtml.CDATANode:
+		c.sb.WriteString(html.EscapeString(n.Data))
+	case xhtml.ElementNode:
+		return c.writeElement(n)
+	}
+	return nil
+}
+
+func (c *tfsHTMLConverter) writeElement(n *xhtml.Node) error {
+	switch n.Name {
+	case "ac:link":
+		return c.writeLink(n)
+	case "ac:image":
+		return c.writeImage(n)
+	case "ac:emoticon":
+		c.writeEmoticon(n)
+		return nil
+	case "ac:structured-macro", "ac:macro":
+		return c.writeMacro(n)
+	case "ac:task-list":
+		return c.writeTaskList(n)
+	case "ac:layout", "ac:layout-section", "ac:layout-cell":
+		return c.writeTag("div", nil, n)
+	case "ac:parameter", "ac:placeholder", "ac:plain-text-body", "ac:plain-text-link-body":
+		return nil
+	case "time":
+		if datetime, ok := n.Attr("datetime"); ok && len(n.Children) == 0 {
+			c.sb.WriteString(html.EscapeString(datetime))
+			return nil
+		}
+	}
+
+	if strings.Contains(n.Name, ":") {
+		// other Confluence specific elements (inline comment markers, rich text bodies, etc.) are replaced with content
+		return c.writeChildren(n)
+	}
+
+	attrs := make([]xhtml.Attr, 0, len(n.Attrs))
+	for _, attr := range n.Attrs {
+		if strings.Contains(attr.Name, ":") {
+			continue
+		}
+		if (n.Name == "a" && attr.Name == "href") || (n.Name == "img" && attr.Name == "src") {
+			attr.Value = c.absoluteURL(attr.Value)
+		}
+		attrs = append(attrs, attr)
+	}
+	return c.writeTag(n.Name, attrs, n)
+}
+
+// writeTag writes the element with converted children of the node.
+func (c *tfsHTMLConverter) writeTag(name string, attrs []xhtml.Attr, content *xhtml.Node) error {
+	c.writeStartTag(name, attrs)
+	if xhtml.IsVoidElement(name) {
+		return nil
+	}
+
+	if content != nil {
+		err := c.writeChildren(content)
+		if err != nil {
+			return err
+		}
+	}
+	c.sb.WriteString("</" + name + ">")
+	return nil
+}
+
+func (c *tfsHTMLConverter) writeStartTag(name string, attrs []xhtml.Attr) {
+	node := xhtml.Node{Type: xhtml.ElementNode, Name: name, Attrs: attrs}
+	c.sb.WriteString(node.HTMLStartTag())
+}
+
+func (c *tfsHTMLConverter) writeLink(n *xhtml.Node) error {
+	var href, text string
+	for _, target := range n.Elements() {
+		switch target.Name {
+		case "ri:page", "ri:blog-post":
+			title, _ := target.Attr("ri:content-title")
+			spaceKey, _ := target.Attr("ri:space-key")
+			href, text = c.pageURL(spaceKey, title), title
+		case "ri:attachment":
+			fileName, _ := target.Attr("ri:filename")
+			href, text = c.attachmentURL(target), fileName
+		case "ri:url":
+			href, _ = target.Attr("ri:value")
+			text = href
+		case "ri:space":
+			spaceKey, _ := target.Attr("ri:space-key")
+			href, text = c.baseURL+"/display/"+url.PathEscape(spaceKey), spaceKey
+		case "ri:user":
+			// mention is replaced with the name, unless it has custom text
+			if body := getLinkBody(n); body != nil {
+				return c.writeChildren(body)
+			}
+			c.sb.WriteString(html.EscapeString(c.userName(target)))
+			return nil
+		}
+	}
+
+	if anchor, ok := n.Attr("ac:anchor"); ok && anchor != "" {
+		if href == "" {
+			href = c.pageURL("", "")
+		}
+		href += "#" + url.PathEscape(anchor)
+	}
+
+	var attrs []xhtml.Attr
+	if href != "" {
+		attrs = append(attrs, xhtml.Attr{Name: "href", Value: href})
+	}
+
+	c.writeStartTag("a", attrs)
+	if body := getLinkBody(n); body != nil {
+		err := c.writeChildren(body)
+		if err != nil {
+			return err
+		}
+	} else {
+		c.sb.WriteString(html.EscapeString(text))
+	}
+	c.sb.WriteString("</a>")
+	return nil
+}
+
+// getLinkBody returns rich or plain text body of the link or nil.
+func getLinkBody(link *xhtml.Node) *xhtml.Node {
+	for _, e := range link.Elements() {
+		if e.Name == "ac:link-body" || e.Name == "ac:plain-text-link-body" {
+			return e
+		}
+	}
+	return nil
+}
+
+func (c *tfsHTMLConverter) writeImage(n *xhtml.Node) error {
+	var src string
+	for _, source := range n.Elements() {
+		switch source.Name {
+		case "ri:url":
+			src, _ = source.Attr("ri:value")
+		case "ri:attachment":
+			image := c.storageImage(source)
+			src = image.URL
+			if c.options.uploadImage != nil {
+				uploaded, err := c.options.uploadImage(image)
+				if err != nil {
+					return err
+				}
+				src = uploaded
+			}
+		}
+	}
+
+	if src == "" {
+		return nil
+	}
+
+	attrs := []xhtml.Attr{{Name: "src", Value: src}}
+	for _, name := range []string{"alt", "title", "width", "height"} {
+		if value, ok := n.Attr("ac:" + name); ok {
+			attrs = append(attrs, xhtml.Attr{Name: name, Value: value})
+		}
+	}
+	c.writeStartTag("img", attrs)
+	return nil
+}
+
+func (c *tfsHTMLConverter) writeEmoticon(n *xhtml.Node) {
+	if fallback, ok := n.Attr("ac:emoji-fallback"); ok && fallback != "" {
+		c.sb.WriteString(html.EscapeString(fallback))
+		return
+	}
+	name, _ := n.Attr("ac:name")
+	c.sb.WriteString(emoticons[name])
+}
+
+func (c *tfsHTMLConverter) writeMacro(n *xhtml.Node) error {
+	name, _ := n.Attr("ac:name")
+	if c.options.macros.IsWorkItemMacro(name) {
+		c.writeWorkItem(getMacroParameter(n, "itemID"))
+		return nil
+	}
+
+	switch name {
+	case "code", "noformat":
+		c.sb.WriteString("<pre>")
+		for _, body := range n.Elements() {
+			if body.Name == "ac:plain-text-body" {
+				c.sb.WriteString(html.EscapeString(body.Text()))
+			}
+		}
+		c.sb.WriteString("</pre>")
+		return nil
+	case "status":
+		title := getMacroParameter(n, "title")
+		if title == "" {
+			title = getMacroParameter(n, "colour")
+		}
+		c.sb.WriteString("<b>[" + html.EscapeString(title) + "]</b>")
+		return nil
+	case "jira":
+		c.sb.WriteString(html.EscapeString(getMacroParameter(n, "key")))
+		return nil
+	case "anchor", "toc", "children", "pagetree", "recently-updated":
+		return nil
+	}
+
+	for _, body := range n.Elements() {
+		if body.Name != "ac:rich-text-body" {
+			continue
+		}
+
+		c.sb.WriteString("<div>")
+		if title := getMacroParameter(n, "title"); title != "" {
+			c.sb.WriteString("<p><b>" + html.EscapeString(title) + "</b></p>")
+		}
+		err := c.writeChildren(body)
+		if err != nil {
+			return err
+		}
+		c.sb.WriteString("</div>")
+	}
+	return nil
+}
+
+func (c *tfsHTMLConverter) writeWorkItem(itemID string) {
+	id, err := strconv.Atoi(strings.TrimSpace(itemID))
+	if err != nil {
+		c.sb.WriteString(html.EscapeString(itemID))
+		return
+	}
+
+	text := "#" + strconv.Itoa(id)
+	if c.options.workItemURL == nil {
+		c.sb.WriteString(text)
+		return
+	}
+	c.sb.WriteString(`<a href="` + html.EscapeString(c.options.workItemURL(id)) + `">` + text + "</a>")
+}
+
+func (c *tfsHTMLConverter) writeTaskList(n *xhtml.Node) error {
+	c.sb.WriteString("<ul>")
+	for _, task := range n.Elements() {
+		if task.Name != "ac:task" {
+			continue
+		}
+
+		mark := "☐"
+		for _, e := range task.Elements() {
+			if e.Name == "ac:task-status" && strings.TrimSpace(e.Text()) == "complete" {
+				mark = "☑"
+			}
+		}
+
+		c.sb.WriteString("<li>" + mark + " ")
+		for _, e := range task.Elements() {
+			if e.Name != "ac:task-body" {
+				continue
+			}
+			err := c.writeChildren(e)
+			if err != nil {
+				return err
+			}
+		}
+		c.sb.WriteString("</li>")
+	}
+	c.sb.WriteString("</ul>")
+	return nil
+}
+
+func (c *tfsHTMLConverter) userName(user *xhtml.Node) string {
+	var u StorageUser
+	u.Key, _ = user.Attr("ri:userkey")
+	u.AccountID, _ = user.Attr("ri:account-id")
+	u.Username, _ = user.Attr("ri:username")
+
+	if c.options.resolveUser != nil {
+		if name := c.options.resolveUser(u); name != "" {
+			return name
+		}
+	}
+
+	query := u.Query()
+	if _, id, ok := strings.Cut(query, ":"); ok {
+		query = id
+	}
+	return "@" + query
+}
+
+// storageImage describes the attachment referenced by ri:attachment element.
+func (c *tfsHTMLConverter) storageImage(attachment *xhtml.Node) StorageImage {
+	image := StorageImage{PageID: c.pageID, SpaceKey: c.spaceKey}
+	image.FileName, _ = attachment.Attr("ri:filename")
+
+	for _, page := range attachment.Elements() {
+		if page.Name == "ri:page" || page.Name == "ri:blog-post" {
+			image.PageID = ""
+			image.PageTitle, _ = page.Attr("ri:content-title")
+			if spaceKey, ok := page.Attr("ri:space-key"); ok {
+				image.SpaceKey = spaceKey
+			}
+		}
+	}
+
+	if image.PageID != "" {
+		image.URL = c.baseURL + "/download/attachments/" + url.PathEscape(image.PageID) + "/" + url.PathEscape(image.FileName)
+	}
+	return image
+}
+
+func (c *tfsHTMLConverter) attachmentURL(attachment *xhtml.Node) string {
+	image := c.storageImage(attachment)
+	if image.URL != "" {
+		return image.URL
+	}
+	return c.pageURL(image.SpaceKey, image.PageTitle)
+}
+
+// pageURL returns URL of the page by title, current page is used if title is empty.
+func (c *tfsHTMLConverter) pageURL(spaceKey, title string) string {
+	if title == "" {
+		return c.baseURL + "/pages/viewpage.action?pageId=" + url.QueryEscape(c.pageID)
+	}
+	if spaceKey == "" {
+		spaceKey = c.spaceKey
+	}
+	return c.baseURL + "/display/" + url.PathEscape(spaceKey) + "/" + url.QueryEscape(title)
+}
+
+// absoluteURL resolves URL relative to the wiki base address.
+func (c *tfsHTMLConverter) absoluteURL(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(ref, "#") {
+		return ref
+	}
+
+	base, err := url.Parse(c.baseURL + "/")
+	if err != nil {
+		return ref
+	}
+	if strings.HasPrefix(ref, "/") {
+		// wiki may be hosted under the path, e.g. /confluence, links relative to the host already contain it
+		base.Path = ""
+	}
+	return base.ResolveReference(u).String()
+}
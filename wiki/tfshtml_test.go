@@ -0,0 +1,85 @@
+package wiki
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+func Test_StorageToTfsHTML(t *testing.T) {
+	page := &goconfluence.Content{
+		ID:    "100",
+		Space: &goconfluence.Space{Key: "DEV"},
+		Links: &goconfluence.Links{Base: "https://wiki.local/"},
+	}
+
+	tests := []struct {
+		name     string
+		storage  string
+		opts     []TfsHTMLOption
+		expected string
+	}{
+		{
+			name:     "page link",
+			storage:  `<p>See <ac:link ac:anchor="API"><ri:page ri:content-title="Feature 1 &amp; 2" /><ac:plain-text-link-body><![CDATA[spec]]></ac:plain-text-link-body></ac:link></p>`,
+			expected: `<p>See <a href="https://wiki.local/display/DEV/Feature+1+%26+2#API">spec</a></p>`,
+		},
+		{
+			name:     "relative links",
+			storage:  `<a href="/pages/viewpage.action?pageId=1">page</a><a href="#top">top</a><a href="https://tfs.local/">tfs</a>`,
+			expected: `<a href="https://wiki.local/pages/viewpage.action?pageId=1">page</a><a href="#top">top</a><a href="https://tfs.local/">tfs</a>`,
+		},
+		{
+			name:     "user mention",
+			storage:  `<p><ac:link><ri:user ri:userkey="abc" /></ac:link>, <ac:link><ri:user ri:username="petrov" /></ac:link></p>`,
+			opts:     []TfsHTMLOption{ResolveUsers(func(user StorageUser) string { return map[string]string{"key:abc": "Ivanov Ivan"}[user.Query()] })},
+			expected: `<p>Ivanov Ivan, @petrov</p>`,
+		},
+		{
+			name:     "code and status macros",
+			storage:  `<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[if a < b {}]]></ac:plain-text-body></ac:structured-macro><p><ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">Green</ac:parameter><ac:parameter ac:name="title">DONE</ac:parameter></ac:structured-macro>&nbsp;<ac:emoticon ac:name="tick" /></p>`,
+			expected: "<pre>if a &lt; b {}</pre><p><b>[DONE]</b>\u00a0✔️</p>",
+		},
+		{
+			name:     "macro bodies",
+			storage:  `<ac:structured-macro ac:name="info"><ac:parameter ac:name="title">Note</ac:parameter><ac:rich-text-body><p>text</p></ac:rich-text-body></ac:structured-macro><ac:structured-macro ac:name="toc" /><ac:structured-macro ac:name="work-item-tfs"><ac:parameter ac:name="itemID">123</ac:parameter></ac:structured-macro>`,
+			expected: `<div><p><b>Note</b></p><p>text</p></div>#123`,
+		},
+		{
+			name:     "task list",
+			storage:  `<ac:task-list><ac:task><ac:task-id>1</ac:task-id><ac:task-status>complete</ac:task-status><ac:task-body>one</ac:task-body></ac:task><ac:task><ac:task-id>2</ac:task-id><ac:task-status>incomplete</ac:task-status><ac:task-body>two</ac:task-body></ac:task></ac:task-list>`,
+			expected: `<ul><li>☑ one</li><li>☐ two</li></ul>`,
+		},
+		{
+			name:     "images without upload",
+			storage:  `<ac:image ac:width="300"><ri:attachment ri:filename="scheme 1.png" /></ac:image><ac:image><ri:url ri:value="https://img.local/a.png" /></ac:image>`,
+			expected: `<img src="https://wiki.local/download/attachments/100/scheme%201.png" width="300"/><img src="https://img.local/a.png"/>`,
+		},
+		{
+			name:    "images uploaded",
+			storage: `<ac:image><ri:attachment ri:filename="a.png"><ri:page ri:content-title="Other" /></ri:attachment></ac:image>`,
+			opts: []TfsHTMLOption{UploadImages(func(image StorageImage) (string, error) {
+				return "https://tfs.local/attachments/" + image.SpaceKey + "/" + image.PageTitle + "/" + image.FileName, nil
+			})},
+			expected: `<img src="https://tfs.local/attachments/DEV/Other/a.png"/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := StorageToTfsHTML(tt.storage, page, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func Test_StorageToTfsHTML_UploadError(t *testing.T) {
+	page := &goconfluence.Content{ID: "100"}
+	_, err := StorageToTfsHTML(`<ac:image><ri:attachment ri:filename="a.png" /></ac:image>`, page, UploadImages(func(image StorageImage) (string, error) {
+		return "", errors.New("upload failed")
+	}))
+	assert.EqualError(t, err, "upload failed")
+}
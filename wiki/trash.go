@@ -0,0 +1,116 @@
+package wiki
+
+import (
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+type PageNode struct {
+	ID       string
+	Title    string
+	Children []*PageNode
+}
+
+// Walk calls fn for the page and all its descendants, children are visited before their parent,
+// so pages can be deleted in the walking order.
+func (n *PageNode) Walk(fn func(node *PageNode, depth int) error) error {
+	return n.walk(fn, 0)
+}
+
+func (n *PageNode) walk(fn func(node *PageNode, depth int) error, depth int) error {
+	for _, child := range n.Children {
+		err := child.walk(fn, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return fn(n, depth)
+}
+
+// GetPageTree returns the page with all its descendants.
+func (a *API) GetPageTree(pageID string) (*PageNode, error) {
+	page, err := a.GetPageByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.getPageTree(page.ID, page.Title)
+}
+
+func (a *API) getPageTree(pageID, title string) (*PageNode, error) {
+	node := &PageNode{
+		ID:    pageID,
+		Title: title,
+	}
+
+	childPages, err := a.GetChildPages(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, childPage := range childPages.Results {
+		child, err := a.getPageTree(childPage.ID, childPage.Title)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node, nil
+}
+
+// GetTrashedPages returns pages of the space in the trash.
+func (a *API) GetTrashedPages(spaceKey string, limit int) ([]goconfluence.Content, error) {
+	result, err := a.GetContent(goconfluence.ContentQuery{
+		SpaceKey: spaceKey,
+		Type:     "page",
+		Status:   "trashed",
+		Limit:    limit,
+		Expand:   []string{"version", "history"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Results, nil
+}
+
+// RestorePage restores the page from the trash.
+func (a *API) RestorePage(pageID string) error {
+	page, err := a.GetContentByID(pageID, goconfluence.ContentQuery{
+		Status: "trashed",
+		Expand: []string{"space", "version"},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := getContentEndpoint(pageID)
+	if err != nil {
+		return err
+	}
+
+	// body is omitted, so the content of restored page is kept
+	type restoreRequest struct {
+		ID      string                `json:"id"`
+		Type    string                `json:"type"`
+		Status  string                `json:"status"`
+		Title   string                `json:"title"`
+		Space   *goconfluence.Space   `json:"space"`
+		Version *goconfluence.Version `json:"version"`
+	}
+
+	_, err = a.SendAnyContentRequest(endpoint, "PUT", restoreRequest{
+		ID:     page.ID,
+		Type:   page.Type,
+		Status: "current",
+		Title:  page.Title,
+		Space: &goconfluence.Space{
+			Key: page.Space.Key,
+		},
+		Version: &goconfluence.Version{
+			Number: page.Version.Number + 1,
+		},
+	})
+
+	return err
+}
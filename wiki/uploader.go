@@ -7,6 +7,15 @@ import (
 )
 
 func UploadContent(api *API, pageID, content, contentType string, opts ...UploadOption) error {
+	if IsNativeMarkdownContentType(contentType) {
+		converted, err := MarkdownToNativeStorage(content)
+		if err != nil {
+			return err
+		}
+		content = converted
+		contentType = "storage"
+	}
+
 	if IsMarkdownContentType(contentType) {
 		content = `` +
 			`<ac:structured-macro ac:name="markdown" ac:schema-version="1" ac:macro-id="` + uuid.NewString() + `">
@@ -41,6 +50,11 @@ func IsMarkdownContentType(contentType string) bool {
 	return contentType == "md" || contentType == "markdown"
 }
 
+// IsNativeMarkdownContentType reports whether Markdown is converted into native storage format instead of the markdown macro.
+func IsNativeMarkdownContentType(contentType string) bool {
+	return contentType == "md-native" || contentType == "markdown-native"
+}
+
 type UploadOptions struct {
 	addTableOfContents bool
 	headerLevel        int
@@ -0,0 +1,539 @@
+// Package xhtml parses and renders Confluence storage format (XHTML) keeping unmodified markup as is.
+package xhtml
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// NodeType is a type of the storage format document node.
+type NodeType int
+
+const (
+	DocumentNode NodeType = iota
+	ElementNode
+	TextNode
+	CDATANode
+	CommentNode
+	// DirectiveNode is a processing instruction or doctype kept as is.
+	DirectiveNode
+)
+
+// voidElements are rendered without end tag in HTML.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// Attr is an attribute of the element, value is unescaped.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Node is a node of Confluence storage format (XHTML) document.
+// Element names keep namespace prefix (e.g. ac:structured-macro), text keeps entities as in source,
+// so unmodified nodes are rendered exactly as they were parsed.
+type Node struct {
+	Type NodeType
+	// Name is the element name with prefix.
+	Name  string
+	Attrs []Attr
+	// Data is raw text, CDATA content, comment content or the whole directive.
+	Data        string
+	SelfClosing bool
+	Parent      *Node
+	Children    []*Node
+
+	// rawStart and rawEnd are source tags of the element, they are reset when element is modified.
+	rawStart string
+	rawEnd   string
+}
+
+// Parse parses storage format page body.
+func Parse(body string) (*Node, error) {
+	doc := &Node{Type: DocumentNode}
+	err := parseInto(doc, body)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// NewText creates text node with escaped text.
+func NewText(text string) *Node {
+	return &Node{Type: TextNode, Data: escapeText(text)}
+}
+
+func parseInto(parent *Node, markup string) error {
+	p := parser{src: markup, current: parent}
+	for p.pos < len(p.src) {
+		err := p.next()
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.current != parent {
+		return fmt.Errorf("element <%s> is not closed", p.current.Name)
+	}
+	return nil
+}
+
+type parser struct {
+	src     string
+	pos     int
+	current *Node
+}
+
+func (p *parser) next() error {
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "<!--"):
+		end := strings.Index(rest[4:], "-->")
+		if end < 0 {
+			return errors.New("comment is not closed")
+		}
+		p.append(&Node{Type: CommentNode, Data: rest[4 : 4+end]})
+		p.pos += 4 + end + 3
+	case strings.HasPrefix(rest, "<![CDATA["):
+		end := strings.Index(rest[9:], "]]>")
+		if end < 0 {
+			return errors.New("CDATA section is not closed")
+		}
+		p.append(&Node{Type: CDATANode, Data: rest[9 : 9+end]})
+		p.pos += 9 + end + 3
+	case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return errors.New("directive is not closed")
+		}
+		p.append(&Node{Type: DirectiveNode, Data: rest[:end+1]})
+		p.pos += end + 1
+	case strings.HasPrefix(rest, "</"):
+		return p.endTag(rest)
+	case len(rest) > 1 && rest[0] == '<' && isNameStartChar(rest[1]):
+		return p.startTag(rest)
+	default:
+		// '<' not starting a tag is kept as text
+		end := strings.IndexByte(rest[1:], '<')
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end++
+		}
+		p.append(&Node{Type: TextNode, Data: rest[:end]})
+		p.pos += end
+	}
+	return nil
+}
+
+func (p *parser) append(node *Node) {
+	p.current.AppendChild(node)
+}
+
+func (p *parser) endTag(rest string) error {
+	end := strings.IndexByte(rest, '>')
+	if end < 0 {
+		return errors.New("end tag is not closed")
+	}
+
+	name := strings.TrimSpace(rest[2:end])
+	if p.current.Type != ElementNode || p.current.Name != name {
+		return fmt.Errorf("unexpected end tag </%s>", name)
+	}
+
+	p.current.rawEnd = rest[:end+1]
+	p.current = p.current.Parent
+	p.pos += end + 1
+	return nil
+}
+
+func (p *parser) startTag(rest string) error {
+	i := 1
+	for i < len(rest) && isNameChar(rest[i]) {
+		i++
+	}
+	node := &Node{Type: ElementNode, Name: rest[1:i]}
+
+	for {
+		for i < len(rest) && isSpace(rest[i]) {
+			i++
+		}
+		if i >= len(rest) {
+			return fmt.Errorf("start tag <%s> is not closed", node.Name)
+		}
+
+		if rest[i] == '>' {
+			i++
+			break
+		}
+		if strings.HasPrefix(rest[i:], "/>") {
+			node.SelfClosing = true
+			i += 2
+			break
+		}
+
+		nameStart := i
+		for i < len(rest) && !isSpace(rest[i]) && rest[i] != '=' && rest[i] != '>' && rest[i] != '/' {
+			i++
+		}
+		attr := Attr{Name: rest[nameStart:i]}
+		if attr.Name == "" {
+			return fmt.Errorf("invalid attribute of <%s>", node.Name)
+		}
+
+		for i < len(rest) && isSpace(rest[i]) {
+			i++
+		}
+		if i < len(rest) && rest[i] == '=' {
+			i++
+			for i < len(rest) && isSpace(rest[i]) {
+				i++
+			}
+			if i >= len(rest) {
+				return fmt.Errorf("start tag <%s> is not closed", node.Name)
+			}
+
+			var value string
+			if quote := rest[i]; quote == '"' || quote == '\'' {
+				end := strings.IndexByte(rest[i+1:], quote)
+				if end < 0 {
+					return fmt.Errorf("attribute %s of <%s> is not closed", attr.Name, node.Name)
+				}
+				value = rest[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				valueStart := i
+				for i < len(rest) && !isSpace(rest[i]) && rest[i] != '>' {
+					i++
+				}
+				value = rest[valueStart:i]
+			}
+			attr.Value = html.UnescapeString(value)
+		}
+
+		node.Attrs = append(node.Attrs, attr)
+	}
+
+	node.rawStart = rest[:i]
+	p.append(node)
+	if !node.SelfClosing {
+		p.current = node
+	}
+	p.pos += i
+	return nil
+}
+
+func isNameStartChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStartChar(c) || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// AppendChild adds the node as the last child.
+func (n *Node) AppendChild(child *Node) {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// IsVoidElement reports whether the HTML element has no end tag, e.g. br and img.
+func IsVoidElement(name string) bool {
+	return voidElements[name]
+}
+
+// Attr returns value of the attribute.
+func (n *Node) Attr(name string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets value of the attribute, adding it if missing.
+func (n *Node) SetAttr(name, value string) {
+	n.rawStart = ""
+	for i := range n.Attrs {
+		if n.Attrs[i].Name == name {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, Attr{Name: name, Value: value})
+}
+
+// Elements returns child elements of the node.
+func (n *Node) Elements() []*Node {
+	var elements []*Node
+	for _, child := range n.Children {
+		if child.Type == ElementNode {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// PrevElement returns the previous sibling element or nil.
+func (n *Node) PrevElement() *Node {
+	if n.Parent == nil {
+		return nil
+	}
+
+	var prev *Node
+	for _, sibling := range n.Parent.Children {
+		if sibling == n {
+			return prev
+		}
+		if sibling.Type == ElementNode {
+			prev = sibling
+		}
+	}
+	return nil
+}
+
+// Find returns all descendant elements matching the predicate in document order.
+func (n *Node) Find(predicate func(e *Node) bool) []*Node {
+	var found []*Node
+	for _, child := range n.Children {
+		if child.Type != ElementNode {
+			continue
+		}
+		if predicate(child) {
+			found = append(found, child)
+		}
+		found = append(found, child.Find(predicate)...)
+	}
+	return found
+}
+
+// FindByName returns all descendant elements with the name (including prefix) in document order.
+func (n *Node) FindByName(name string) []*Node {
+	return n.Find(func(e *Node) bool { return e.Name == name })
+}
+
+// Closest returns the nearest ancestor element with the name or nil.
+func (n *Node) Closest(name string) *Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == ElementNode && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Root returns the document node the node belongs to.
+func (n *Node) Root() *Node {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// Text returns unescaped text of the node and its descendants, CDATA included.
+func (n *Node) Text() string {
+	switch n.Type {
+	case TextNode:
+		return html.UnescapeString(n.Data)
+	case CDATANode:
+		return n.Data
+	case CommentNode, DirectiveNode:
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, child := range n.Children {
+		sb.WriteString(child.Text())
+	}
+	return sb.String()
+}
+
+// String renders the node in storage format.
+func (n *Node) String() string {
+	var sb strings.Builder
+	n.render(&sb)
+	return sb.String()
+}
+
+// InnerXML renders children of the node in storage format.
+func (n *Node) InnerXML() string {
+	var sb strings.Builder
+	for _, child := range n.Children {
+		child.render(&sb)
+	}
+	return sb.String()
+}
+
+// SetInnerXML replaces children of the node with the parsed markup.
+func (n *Node) SetInnerXML(markup string) error {
+	fragment := &Node{Type: DocumentNode}
+	err := parseInto(fragment, markup)
+	if err != nil {
+		return err
+	}
+
+	n.Children = nil
+	for _, child := range fragment.Children {
+		n.AppendChild(child)
+	}
+
+	if n.SelfClosing && len(n.Children) > 0 {
+		n.SelfClosing = false
+		n.rawStart = ""
+	}
+	return nil
+}
+
+func (n *Node) render(sb *strings.Builder) {
+	switch n.Type {
+	case TextNode, DirectiveNode:
+		sb.WriteString(n.Data)
+	case CDATANode:
+		sb.WriteString("<![CDATA[" + n.Data + "]]>")
+	case CommentNode:
+		sb.WriteString("<!--" + n.Data + "-->")
+	case DocumentNode:
+		for _, child := range n.Children {
+			child.render(sb)
+		}
+	case ElementNode:
+		if n.rawStart != "" {
+			sb.WriteString(n.rawStart)
+		} else {
+			n.renderStartTag(sb, true)
+		}
+
+		if n.SelfClosing {
+			return
+		}
+
+		for _, child := range n.Children {
+			child.render(sb)
+		}
+
+		if n.rawEnd != "" {
+			sb.WriteString(n.rawEnd)
+		} else {
+			sb.WriteString("</" + n.Name + ">")
+		}
+	}
+}
+
+// HTMLStartTag renders the start tag of the element as HTML, void elements are self-closing.
+func (n *Node) HTMLStartTag() string {
+	var sb strings.Builder
+	n.renderStartTag(&sb, false)
+	return sb.String()
+}
+
+func (n *Node) renderStartTag(sb *strings.Builder, xml bool) {
+	sb.WriteString("<" + n.Name)
+	for _, attr := range n.Attrs {
+		sb.WriteString(" " + attr.Name + `="` + html.EscapeString(attr.Value) + `"`)
+	}
+	switch {
+	case xml && n.SelfClosing:
+		sb.WriteString(" />")
+	case !xml && voidElements[n.Name]:
+		sb.WriteString("/>")
+	default:
+		sb.WriteString(">")
+	}
+}
+
+// InnerHTML renders children of the node as HTML, e.g. for TFS work item description:
+// entities are resolved, CDATA becomes text and only void elements are self-closing.
+func (n *Node) InnerHTML() string {
+	var sb strings.Builder
+	for _, child := range n.Children {
+		child.renderHTML(&sb)
+	}
+	return sb.String()
+}
+
+func (n *Node) renderHTML(sb *strings.Builder) {
+	switch n.Type {
+	case TextNode:
+		sb.WriteString(html.EscapeString(html.UnescapeString(n.Data)))
+	case CDATANode:
+		sb.WriteString(html.EscapeString(n.Data))
+	case CommentNode:
+		sb.WriteString("<!--" + n.Data + "-->")
+	case ElementNode:
+		n.renderStartTag(sb, false)
+		if voidElements[n.Name] {
+			return
+		}
+		for _, child := range n.Children {
+			child.renderHTML(sb)
+		}
+		sb.WriteString("</" + n.Name + ">")
+	}
+}
+
+// TableRows returns rows of the table element, nested tables are not included.
+func (n *Node) TableRows() []*Node {
+	var rows []*Node
+	for _, child := range n.Elements() {
+		switch child.Name {
+		case "tr":
+			rows = append(rows, child)
+		case "thead", "tbody", "tfoot":
+			for _, tr := range child.Elements() {
+				if tr.Name == "tr" {
+					rows = append(rows, tr)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// RowCells returns header and data cells of the table row.
+func (n *Node) RowCells() []*Node {
+	var cells []*Node
+	for _, child := range n.Elements() {
+		if child.Name == "td" || child.Name == "th" {
+			cells = append(cells, child)
+		}
+	}
+	return cells
+}
+
+// TableCell returns the cell of the table element by zero based row and column numbers.
+func (n *Node) TableCell(row, col int) (*Node, error) {
+	rows := n.TableRows()
+	if row < 0 || row >= len(rows) {
+		return nil, fmt.Errorf("table row %d not found", row)
+	}
+
+	cells := rows[row].RowCells()
+	if col < 0 || col >= len(cells) {
+		return nil, fmt.Errorf("table cell %d of row %d not found", col, row)
+	}
+
+	return cells[col], nil
+}
+
+// SetTableCell replaces content of the table cell by zero based row and column numbers.
+func (n *Node) SetTableCell(row, col int, markup string) error {
+	cell, err := n.TableCell(row, col)
+	if err != nil {
+		return err
+	}
+	return cell.SetInnerXML(markup)
+}
@@ -0,0 +1,49 @@
+package xhtml
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse_RoundTrip(t *testing.T) {
+	for _, path := range []string{"../../testdata/wiki_page_1.html", "../../testdata/wiki_page_2.html"} {
+		file, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		doc, err := Parse(string(file))
+		assert.NoError(t, err)
+		assert.Equal(t, string(file), doc.String(), path)
+	}
+}
+
+func Test_Parse_TableInMacro(t *testing.T) {
+	body := `<ac:structured-macro ac:name="expand"><ac:parameter ac:name="title">Задачи</ac:parameter><ac:rich-text-body>` +
+		`<table><tbody><tr><th>A</th><th>B</th></tr>` +
+		`<tr><td><table><tbody><tr><td>nested</td></tr></tbody></table></td><td>&nbsp;<ac:emoticon ac:name="tick" /></td></tr>` +
+		`</tbody></table></ac:rich-text-body></ac:structured-macro>` +
+		`<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[if a < b && c > d {}]]></ac:plain-text-body></ac:structured-macro>`
+
+	doc, err := Parse(body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, doc.String())
+
+	tables := doc.FindByName("table")
+	assert.Len(t, tables, 2)
+	assert.Len(t, tables[0].TableRows(), 2)
+
+	cell, err := tables[0].TableCell(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "\u00a0", cell.Text())
+	assert.Equal(t, "\u00a0<ac:emoticon ac:name=\"tick\"></ac:emoticon>", cell.InnerHTML())
+
+	err = tables[0].SetTableCell(1, 1, `<p><ac:structured-macro ac:name="status"><ac:parameter ac:name="title">OK</ac:parameter></ac:structured-macro></p>`)
+	assert.NoError(t, err)
+
+	expected := strings.Replace(body, `&nbsp;<ac:emoticon ac:name="tick" />`,
+		`<p><ac:structured-macro ac:name="status"><ac:parameter ac:name="title">OK</ac:parameter></ac:structured-macro></p>`, 1)
+	assert.Equal(t, expected, doc.String())
+	assert.Equal(t, "if a < b && c > d {}", doc.FindByName("ac:plain-text-body")[0].Text())
+}